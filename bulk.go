@@ -0,0 +1,378 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"fourjawaly-cli/fourjawaly"
+	"fourjawaly-cli/fourjawaly/sms"
+)
+
+// Defaults for --concurrency and --rate: enough to move a large list along
+// without tripping the API's throttling.
+const (
+	defaultBulkConcurrency = 4
+	defaultBulkRate        = 10
+)
+
+type bulkOptions struct {
+	DryRun            bool
+	DuplicatesRemoved int
+	Concurrency       int
+	Rate              float64
+	JournalPath       string
+	NoJournal         bool
+	ReportPath        string
+}
+
+// addBulkFlags registers the flags shared by sms send and sms resume.
+func addBulkFlags(fs *flag.FlagSet) *bulkOptions {
+	opts := &bulkOptions{}
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "معاينة بدون إرسال")
+	fs.IntVar(&opts.Concurrency, "concurrency", defaultBulkConcurrency, "عدد الطلبات المتزامنة في الإرسال المجمّع")
+	fs.Float64Var(&opts.Rate, "rate", defaultBulkRate, "أقصى عدد طلبات في الثانية في الإرسال المجمّع (0 بلا حد)")
+	fs.StringVar(&opts.JournalPath, "journal", "", "ملف تتبع الإرسال المجمّع للاستئناف (الافتراضي تلقائي)")
+	fs.BoolVar(&opts.NoJournal, "no-journal", false, "تعطيل ملف التتبع")
+	fs.StringVar(&opts.ReportPath, "report", "", "ملف تقرير لكل رقم: csv أو jsonl حسب الامتداد")
+	return opts
+}
+
+func (o *bulkOptions) validate() error {
+	if o.Concurrency < 1 {
+		return usageErrorf("--concurrency يجب أن يكون 1 أو أكثر")
+	}
+	if o.Rate < 0 {
+		return usageErrorf("--rate لا يقبل قيمة سالبة")
+	}
+	return nil
+}
+
+// createReport opens the --report file, or returns a nil writer without it.
+func (o *bulkOptions) createReport() (*reportWriter, error) {
+	if o.ReportPath == "" {
+		return nil, nil
+	}
+	return createReport(o.ReportPath)
+}
+
+// smsChunk is one request of a bulk send; Index is stable across resumes.
+type smsChunk struct {
+	Index    int
+	Messages []sms.Message
+}
+
+type chunkResult struct {
+	Index      int
+	StatusCode int
+	Result     *sms.SendResult
+	Messages   []sms.Message
+	Numbers    []string
+	Error      error
+}
+
+// chunkOutcome classifies what a chunk request did. Every chunkResult maps
+// to exactly one outcome, so the numbers in a summary always add up.
+type chunkOutcome int
+
+const (
+	outcomeSuccess        chunkOutcome = iota
+	outcomeAPIRejected                 // 2xx with err_text
+	outcomeHTTPError                   // non-2xx status
+	outcomeTransportError              // no response at all
+	outcomeUnknown                     // a response that says neither: the numbers may or may not be sent
+)
+
+var chunkOutcomeNames = [...]string{
+	outcomeSuccess:        "success",
+	outcomeAPIRejected:    "api-rejected",
+	outcomeHTTPError:      "http-error",
+	outcomeTransportError: "transport-error",
+	outcomeUnknown:        "unknown",
+}
+
+func (o chunkOutcome) String() string {
+	return chunkOutcomeNames[o]
+}
+
+// Failed reports whether the chunk certainly was not sent and is safe to retry.
+func (o chunkOutcome) Failed() bool {
+	return o == outcomeAPIRejected || o == outcomeHTTPError || o == outcomeTransportError
+}
+
+func classifyChunk(cr chunkResult) chunkOutcome {
+	var (
+		apiErr *fourjawaly.APIError
+		netErr *fourjawaly.NetworkError
+	)
+	switch {
+	case errors.As(cr.Error, &apiErr):
+		if apiErr.Rejected() {
+			return outcomeAPIRejected
+		}
+		return outcomeHTTPError
+	case errors.As(cr.Error, &netErr):
+		return outcomeTransportError
+	case cr.Error != nil, cr.Result == nil:
+		// e.g. a 2xx body that does not decode: the API may have accepted it.
+		return outcomeUnknown
+	case cr.Result.ErrText() != "":
+		return outcomeAPIRejected
+	case len(cr.Result.Messages) == 0:
+		return outcomeUnknown
+	}
+	return outcomeSuccess
+}
+
+// bulkTotals accumulates the outcome of one run over a set of chunks, in
+// numbers rather than chunks.
+type bulkTotals struct {
+	Success     int
+	Failed      int
+	Unknown     int
+	Outcomes    map[string]int
+	JobIDs      []string
+	FirstErr    error
+	Interrupted bool
+}
+
+func (t *bulkTotals) add(cr chunkResult) {
+	outcome := classifyChunk(cr)
+	n := len(cr.Numbers)
+	if t.Outcomes == nil {
+		t.Outcomes = map[string]int{}
+	}
+	t.Outcomes[outcome.String()] += n
+	if t.FirstErr == nil {
+		t.FirstErr = cr.Error
+	}
+
+	var apiErr *fourjawaly.APIError
+	errors.As(cr.Error, &apiErr)
+	switch outcome {
+	case outcomeSuccess:
+		t.Success += n
+	case outcomeAPIRejected:
+		t.Failed += n
+		// A rejection may be known from the error alone, with no decoded result.
+		var msg string
+		switch {
+		case apiErr != nil:
+			msg = apiErr.Message
+		case cr.Result != nil:
+			msg = cr.Result.ErrText()
+		}
+		fmt.Fprintf(os.Stderr, "خطأ API: %s\n", msg)
+	case outcomeHTTPError:
+		t.Failed += n
+		fmt.Fprintf(os.Stderr, "خطأ HTTP %d لمجموعة %d أرقام\n", apiErr.StatusCode, n)
+	case outcomeTransportError:
+		t.Failed += n
+		fmt.Fprintf(os.Stderr, "خطأ في مجموعة (%d أرقام): %v\n", n, cr.Error)
+	case outcomeUnknown:
+		t.Unknown += n
+		reason := "رد بدون messages"
+		if cr.Error != nil {
+			reason = cr.Error.Error()
+		}
+		fmt.Fprintf(os.Stderr, "نتيجة غير مؤكدة لمجموعة %d أرقام: %s\n", n, reason)
+	}
+
+	// An unknown outcome keeps its job_id: it is the only handle to find
+	// out what happened.
+	if cr.Result != nil && (outcome == outcomeSuccess || outcome == outcomeUnknown) {
+		if jid := cr.Result.JobID.String(); jid != "" {
+			t.JobIDs = append(t.JobIDs, jid)
+		}
+	}
+}
+
+func sendSMSChunked(cfg smsConfig, messages []sms.Message, opts *bulkOptions) error {
+	chunkSize := sms.MaxNumbersPerRequest
+	packed := packSMSChunks(messages, chunkSize)
+	chunks := make([]smsChunk, len(packed))
+	for i, msgs := range packed {
+		chunks[i] = smsChunk{Index: i, Messages: msgs}
+	}
+	numbers := countNumbers(messages)
+
+	progressf("إرسال مجمّع: %d رقم في %d مجموعة...\n", numbers, len(chunks))
+
+	if opts.DryRun {
+		est := estimateMessages(messages)
+		if output != outputText {
+			return emitValue(map[string]any{
+				"dry_run":            true,
+				"numbers":            numbers,
+				"duplicates_removed": opts.DuplicatesRemoved,
+				"texts":              len(messages),
+				"chunks":             len(chunks),
+				"chunk_size":         chunkSize,
+				"concurrency":        opts.Concurrency,
+				"rate":               opts.Rate,
+				"estimate":           est,
+			}, nil)
+		}
+		fmt.Println("[dry-run] لن يتم الإرسال الفعلي")
+		fmt.Printf("[dry-run] %d مجموعة × حتى %d رقم\n", len(chunks), chunkSize)
+		fmt.Printf("[dry-run] %s\n", est.Summary())
+		return nil
+	}
+
+	var journal *journalWriter
+	if !opts.NoJournal {
+		var err error
+		if journal, err = createJournal(opts.JournalPath, cfg, chunks, numbers); err != nil {
+			return err
+		}
+		defer journal.Close()
+		progressf("ملف التتبع: %s\n", journal.Path())
+	}
+	report, err := opts.createReport()
+	if err != nil {
+		return err
+	}
+	defer report.Close()
+
+	totals := runSMSChunks(cfg, chunks, opts, journal, report)
+	return finishBulk(totals, numbers, 0, opts, journal)
+}
+
+// runSMSChunks sends chunks through a bounded, rate-limited worker pool. On
+// SIGINT/SIGTERM no new chunks are started; those in flight are allowed to
+// finish so that the journal and report record their outcome.
+func runSMSChunks(cfg smsConfig, chunks []smsChunk, opts *bulkOptions, journal *journalWriter, report *reportWriter) bulkTotals {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := cfg.client()
+	limiter := newRateLimiter(opts.Rate)
+	workers := min(max(opts.Concurrency, 1), len(chunks))
+	jobs := make(chan smsChunk)
+	resultsChan := make(chan chunkResult, len(chunks))
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range jobs {
+				if err := limiter.Wait(ctx); err != nil {
+					continue
+				}
+				journal.Started(chunk.Index)
+				cr := sendSMSOneChunk(client, chunk.Messages)
+				cr.Index = chunk.Index
+				journal.Result(cr)
+				report.Write(cr)
+				resultsChan <- cr
+			}
+		}()
+	}
+
+	go func() {
+		defer func() {
+			close(jobs)
+			wg.Wait()
+			close(resultsChan)
+		}()
+		for _, chunk := range chunks {
+			select {
+			case jobs <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var totals bulkTotals
+	for cr := range resultsChan {
+		totals.add(cr)
+	}
+	totals.Interrupted = ctx.Err() != nil
+	return totals
+}
+
+// finishBulk prints the summary of a run and turns it into the command's
+// error. previous counts numbers already delivered by earlier runs of a
+// resumed campaign.
+func finishBulk(totals bulkTotals, numbers, previous int, opts *bulkOptions, journal *journalWriter) error {
+	summary := map[string]any{
+		"نجح":      totals.Success,
+		"فشل":      totals.Failed,
+		"غير مؤكد": totals.Unknown,
+		"الإجمالي": numbers,
+		"مكرر":     opts.DuplicatesRemoved,
+		"النتائج":  totals.Outcomes,
+		"job_ids":  totals.JobIDs,
+	}
+	if previous > 0 {
+		summary["سابقًا"] = previous
+	}
+	// Interrupted runs leave numbers that got no request in this run.
+	// Uncertain chunks skipped by resume are counted as unknown instead.
+	if unsent := numbers - previous - totals.Success - totals.Failed - totals.Unknown; unsent > 0 {
+		summary["لم يُرسل"] = unsent
+	}
+	if journal != nil {
+		summary["journal"] = journal.Path()
+	}
+	summaryTable := &table{
+		header: []string{"success", "failed", "unknown", "total", "duplicates_removed", "job_ids"},
+		rows: [][]string{{
+			strconv.Itoa(totals.Success),
+			strconv.Itoa(totals.Failed),
+			strconv.Itoa(totals.Unknown),
+			strconv.Itoa(numbers),
+			strconv.Itoa(opts.DuplicatesRemoved),
+			strings.Join(totals.JobIDs, " "),
+		}},
+	}
+	if err := emitValue(summary, summaryTable); err != nil {
+		return err
+	}
+
+	if totals.Interrupted {
+		if journal != nil {
+			progressf("للاستئناف: 4jawaly-cli sms resume %s\n", journal.Path())
+		}
+		return errInterrupted
+	}
+	if totals.Failed > 0 {
+		if journal != nil {
+			progressf("لإعادة المجموعات الفاشلة: 4jawaly-cli sms resume %s\n", journal.Path())
+		}
+		if totals.Success == 0 && totals.Unknown == 0 && previous == 0 && totals.FirstErr != nil {
+			return totals.FirstErr
+		}
+		return &partialFailureError{Success: previous + totals.Success, Failed: totals.Failed, Total: numbers}
+	}
+	if totals.Unknown > 0 {
+		return &unknownResultError{Unknown: totals.Unknown, Total: numbers}
+	}
+	return nil
+}
+
+func sendSMSOneChunk(client *sms.Client, messages []sms.Message) chunkResult {
+	res, err := client.Send(context.Background(), sms.SendRequest{Messages: messages})
+	cr := chunkResult{Result: res, Messages: messages, Numbers: chunkNumbers(messages), Error: err}
+	if res != nil {
+		cr.StatusCode = res.StatusCode
+	}
+	return cr
+}
+
+func chunkNumbers(messages []sms.Message) []string {
+	numbers := make([]string, 0, countNumbers(messages))
+	for _, m := range messages {
+		numbers = append(numbers, m.Numbers...)
+	}
+	return numbers
+}
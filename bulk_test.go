@@ -0,0 +1,132 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fourjawaly-cli/fourjawaly"
+	"fourjawaly-cli/fourjawaly/sms"
+)
+
+func TestClassifyChunk(t *testing.T) {
+	messages := []sms.Message{{Text: "hi", Sender: "S", Numbers: []string{"966500000001", "966500000002", "966500000003"}}}
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		network bool
+		// result is used as is instead of sending a request when set.
+		result *chunkResult
+		want   chunkOutcome
+		totals bulkTotals
+	}{
+		{
+			name:   "2xx with messages",
+			status: http.StatusOK,
+			body:   `{"job_id":"42","messages":[{"inserted_numbers":3}]}`,
+			want:   outcomeSuccess,
+			totals: bulkTotals{Success: 3, JobIDs: []string{"42"}},
+		},
+		{
+			name:   "2xx with err_text",
+			status: http.StatusOK,
+			body:   `{"messages":[{"inserted_numbers":0,"err_text":"sender not approved"}]}`,
+			want:   outcomeAPIRejected,
+			totals: bulkTotals{Failed: 3},
+		},
+		{
+			name:   "2xx with empty messages",
+			status: http.StatusOK,
+			body:   `{"job_id":"43","messages":[]}`,
+			want:   outcomeUnknown,
+			totals: bulkTotals{Unknown: 3, JobIDs: []string{"43"}},
+		},
+		{
+			name:   "2xx without messages",
+			status: http.StatusOK,
+			body:   `{"message":"ok"}`,
+			want:   outcomeUnknown,
+			totals: bulkTotals{Unknown: 3},
+		},
+		{
+			name:   "2xx body that does not decode",
+			status: http.StatusOK,
+			body:   `<html>ok</html>`,
+			want:   outcomeUnknown,
+			totals: bulkTotals{Unknown: 3},
+		},
+		{
+			name:   "non-2xx",
+			status: http.StatusUnprocessableEntity,
+			body:   `{"message":"invalid numbers"}`,
+			want:   outcomeHTTPError,
+			totals: bulkTotals{Failed: 3},
+		},
+		{
+			name:   "5xx",
+			status: http.StatusBadGateway,
+			body:   `bad gateway`,
+			want:   outcomeHTTPError,
+			totals: bulkTotals{Failed: 3},
+		},
+		{
+			name:    "network error",
+			network: true,
+			want:    outcomeTransportError,
+			totals:  bulkTotals{Failed: 3},
+		},
+		{
+			name: "rejection known from the error alone",
+			result: &chunkResult{
+				Numbers: messages[0].Numbers,
+				Error:   &fourjawaly.APIError{StatusCode: http.StatusOK, Message: "rejected"},
+			},
+			want:   outcomeAPIRejected,
+			totals: bulkTotals{Failed: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cr chunkResult
+			if tt.result != nil {
+				cr = *tt.result
+			} else {
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(tt.status)
+					w.Write([]byte(tt.body))
+				}))
+				if tt.network {
+					srv.Close()
+				} else {
+					defer srv.Close()
+				}
+				client := sms.NewClient("k", "s", sms.WithBaseURL(srv.URL), sms.WithRetry(fourjawaly.RetryPolicy{}))
+				cr = sendSMSOneChunk(client, messages)
+			}
+
+			if got := classifyChunk(cr); got != tt.want {
+				t.Fatalf("classifyChunk = %s, want %s (err: %v)", got, tt.want, cr.Error)
+			}
+
+			var totals bulkTotals
+			totals.add(cr)
+			if totals.Success != tt.totals.Success || totals.Failed != tt.totals.Failed || totals.Unknown != tt.totals.Unknown {
+				t.Errorf("totals = %d/%d/%d success/failed/unknown, want %d/%d/%d",
+					totals.Success, totals.Failed, totals.Unknown,
+					tt.totals.Success, tt.totals.Failed, tt.totals.Unknown)
+			}
+			if got := totals.Outcomes[tt.want.String()]; got != len(messages[0].Numbers) {
+				t.Errorf("Outcomes[%s] = %d, want %d", tt.want, got, len(messages[0].Numbers))
+			}
+			if len(totals.JobIDs) != len(tt.totals.JobIDs) || (len(totals.JobIDs) > 0 && totals.JobIDs[0] != tt.totals.JobIDs[0]) {
+				t.Errorf("JobIDs = %v, want %v", totals.JobIDs, tt.totals.JobIDs)
+			}
+			if want := tt.want.Failed(); want != (tt.totals.Failed > 0) {
+				t.Errorf("%s.Failed() = %v", tt.want, want)
+			}
+		})
+	}
+}
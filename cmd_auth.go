@@ -0,0 +1,261 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func runAuth(args []string) error {
+	if len(args) == 0 {
+		printAuthUsage(os.Stderr)
+		return usageErrorf("مطلوب أمر فرعي لـ auth")
+	}
+
+	switch args[0] {
+	case "login":
+		return runAuthLogin(args[1:])
+	case "logout":
+		return runAuthLogout(args[1:])
+	case "status":
+		return runAuthStatus(args[1:])
+	case "verify":
+		return runAuthVerify(args[1:])
+	case "help", "-h", "--help":
+		printAuthUsage(os.Stdout)
+		return nil
+	default:
+		return usageErrorf("أمر auth غير معروف %q", args[0])
+	}
+}
+
+// authProfile is the profile auth commands act on; unlike activeProfile it
+// may name a profile that does not exist yet.
+func authProfile() (*configFile, string, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	return cfg, cfg.selectedProfileName(), nil
+}
+
+func runAuthLogin(args []string) error {
+	fs := flag.NewFlagSet("auth login", flag.ContinueOnError)
+	appKeyFlag := fs.String("app-key", "", "مفتاح API (يُطلب إن لم يُمرَّر)")
+	storeFlag := fs.String("store", "auto", "مكان الحفظ: auto|keyring|file")
+	addSecretFileFlag(fs)
+	addGlobalFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	store, err := pickCredentialStore(*storeFlag)
+	if err != nil {
+		return err
+	}
+	_, name, err := authProfile()
+	if err != nil {
+		return err
+	}
+
+	c := credentials{AppKey: trimFlag(appKeyFlag)}
+	if c.AppKey == "" {
+		if c.AppKey, err = promptLine("App key", false); err != nil {
+			return err
+		}
+	}
+	if apiSecretFile != "" {
+		c.APISecret, err = readSecretFile(apiSecretFile, "--api-secret-file")
+	} else {
+		c.APISecret, err = promptLine("API secret", true)
+	}
+	if err != nil {
+		return err
+	}
+	if c.AppKey == "" || c.APISecret == "" {
+		return validationErrorf("مطلوب app key و api secret")
+	}
+
+	if err := store.Set(name, c); err != nil {
+		return err
+	}
+	// Drop older copies so a stale secret in another store cannot shadow
+	// this one.
+	for _, other := range credentialStores() {
+		if other.Name() != store.Name() {
+			if _, err := other.Delete(name); err != nil {
+				progressf("تحذير: تعذر حذف النسخة القديمة من %s: %v\n", other.Name(), err)
+			}
+		}
+	}
+
+	progressf("تم حفظ بيانات الدخول للملف %q في %s\n", name, store.Name())
+	return emitValue(map[string]any{"profile": name, "store": store.Name()}, &table{
+		header: []string{"profile", "store"},
+		rows:   [][]string{{name, store.Name()}},
+	})
+}
+
+func pickCredentialStore(name string) (credentialStore, error) {
+	switch name {
+	case "auto", storeKeyring, storeFile:
+	default:
+		return nil, usageErrorf("--store غير معروف %q (المتاح: auto, keyring, file)", name)
+	}
+	keyring := keyringStore{}
+	if name == storeKeyring && !keyring.available() {
+		return nil, validationErrorf("keyring غير متاح: يحتاج secret-tool وجلسة D-Bus (استخدم --store file)")
+	}
+	if name != storeFile && keyring.available() {
+		return keyring, nil
+	}
+	return newFileStore()
+}
+
+func runAuthLogout(args []string) error {
+	fs := flag.NewFlagSet("auth logout", flag.ContinueOnError)
+	addGlobalFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	_, name, err := authProfile()
+	if err != nil {
+		return err
+	}
+
+	removed := []string{}
+	for _, s := range credentialStores() {
+		ok, err := s.Delete(name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			removed = append(removed, s.Name())
+		}
+	}
+	if len(removed) == 0 {
+		progressf("لا توجد بيانات دخول محفوظة للملف %q\n", name)
+	} else {
+		progressf("تم حذف بيانات الدخول للملف %q من %s\n", name, strings.Join(removed, ", "))
+	}
+	return emitValue(map[string]any{"profile": name, "removed_from": removed}, &table{
+		header: []string{"profile", "removed_from"},
+		rows:   [][]string{{name, strings.Join(removed, ",")}},
+	})
+}
+
+// credentialSource is where one credential would be taken from, without
+// decrypting anything.
+type credentialSource struct {
+	Field  string `json:"field"`
+	Source string `json:"source"`
+	Value  string `json:"value,omitempty"`
+}
+
+func runAuthStatus(args []string) error {
+	fs := flag.NewFlagSet("auth status", flag.ContinueOnError)
+	addGlobalFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	cfg, name, err := authProfile()
+	if err != nil {
+		return err
+	}
+	p := cfg.Profiles[name]
+	if p == nil {
+		p = &profile{Name: name}
+	}
+
+	stored := ""
+	storeNames := []string{}
+	for _, s := range credentialStores() {
+		storeNames = append(storeNames, s.Name())
+		if stored == "" && s.Has(name) {
+			stored = s.Name()
+		}
+	}
+
+	sources := []credentialSource{
+		sourceOf("app_key", p.AppKey, stored, "FOURJAWALY_APP_KEY", "FOURJAWALY_APP_KEY_FILE", "APP_KEY"),
+		sourceOf("api_secret", p.APISecret, stored, "FOURJAWALY_API_SECRET", "FOURJAWALY_API_SECRET_FILE", "API_SECRET"),
+		sourceOf("project_id", p.ProjectID, "", "FOURJAWALY_WHATSAPP_PROJECT_ID", "PROJECT_ID"),
+	}
+	// Only the app key is shown, and only its start; secret file paths are
+	// shown as they are.
+	if !strings.HasSuffix(sources[0].Source, "_FILE") {
+		sources[0].Value = maskValue(sources[0].Value)
+	}
+	if !strings.HasSuffix(sources[1].Source, "_FILE") {
+		sources[1].Value = ""
+	}
+
+	t := &table{header: []string{"field", "source", "value"}}
+	for _, s := range sources {
+		t.rows = append(t.rows, []string{s.Field, s.Source, s.Value})
+	}
+	if !strings.HasPrefix(sources[0].Source, "missing") && !strings.HasPrefix(sources[1].Source, "missing") {
+		progressf("الملف %q: بيانات الدخول موجودة\n", name)
+	} else {
+		progressf("الملف %q: بيانات الدخول ناقصة، نفّذ: 4jawaly-cli auth login --profile %s\n", name, name)
+	}
+	return emitValue(map[string]any{
+		"profile":     name,
+		"credentials": sources,
+		"stores":      storeNames,
+	}, t)
+}
+
+// sourceOf mirrors the lookup order of resolveAppKey for one field.
+func sourceOf(field, fromConfig, stored string, envs ...string) credentialSource {
+	for _, env := range envs {
+		if v := envOrDefault(env, ""); v != "" {
+			return credentialSource{Field: field, Source: "env " + env, Value: v}
+		}
+	}
+	if stored != "" {
+		return credentialSource{Field: field, Source: stored}
+	}
+	if fromConfig != "" {
+		return credentialSource{Field: field, Source: "config", Value: fromConfig}
+	}
+	return credentialSource{Field: field, Source: "missing"}
+}
+
+func maskValue(v string) string {
+	runes := []rune(v)
+	if len(runes) == 0 {
+		return ""
+	}
+	if len(runes) <= 4 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:4]) + strings.Repeat("*", min(len(runes)-4, 8))
+}
+
+func printAuthUsage(w io.Writer) {
+	fmt.Fprintln(w, "أوامر auth:")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "  4jawaly-cli auth login  [--profile <اسم>] [--app-key <مفتاح>] [--api-secret-file <ملف>] [--store auto|keyring|file]")
+	fmt.Fprintln(w, "  4jawaly-cli auth logout [--profile <اسم>]")
+	fmt.Fprintln(w, "  4jawaly-cli auth status [--profile <اسم>]")
+	fmt.Fprintln(w, "  4jawaly-cli auth verify [--profile <اسم>] [--project-id <رقم>]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "يطلب login سر API بدون إظهاره، ويحفظه في keyring النظام (Secret Service عبر secret-tool)")
+	fmt.Fprintln(w, "إن كان متاحًا، وإلا في ملف مشفر بكلمة مرور (~/.config/4jawaly/credentials.json،")
+	fmt.Fprintln(w, "أو $XDG_CONFIG_HOME/4jawaly/credentials.json إن كان معرّفًا).")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "يتحقق verify من بيانات الدخول عبر طلب قراءة فقط لكل من SMS و WhatsApp، ويعرض")
+	fmt.Fprintln(w, "النتيجة لكل من sms_auth و wa_auth و wa_project: ok|invalid|missing|skipped|error.")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "خيارات:")
+	fmt.Fprintln(w, "  --api-secret-file  قراءة السر من ملف بدل السؤال")
+	fmt.Fprintln(w, "  --store        auto (الافتراضي): keyring إن كان متاحًا وإلا file")
+	fmt.Fprintln(w, "  --profile      ملف التعريف (أو FOURJAWALY_PROFILE، الافتراضي default_profile ثم default)")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "متغيرات البيئة:")
+	fmt.Fprintln(w, "  FOURJAWALY_PASSPHRASE        كلمة مرور الملف المشفر للتشغيل بدون terminal")
+	fmt.Fprintln(w, "  FOURJAWALY_CREDENTIALS_FILE  مسار الملف المشفر بدل المسار الافتراضي")
+}
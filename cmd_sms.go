@@ -1,30 +1,44 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"net/http"
-	"net/url"
 	"os"
+	"strconv"
 	"strings"
-	"sync"
+	"time"
+
+	"fourjawaly-cli/fourjawaly/sms"
 )
 
-const defaultSMSBaseURL = "https://api-sms.4jawaly.com/api/v1"
+const defaultSMSBaseURL = sms.DefaultBaseURL
 
 type smsConfig struct {
 	AppKey    string
 	APISecret string
 	BaseURL   string
+	// Sender is the profile's default sender; --sender and the environment
+	// override it.
+	Sender string
 }
 
 func resolveSMSConfig(appKeyFlag, apiSecretFlag, baseURLFlag string) (smsConfig, error) {
+	p, err := activeProfile()
+	if err != nil {
+		return smsConfig{}, err
+	}
 	cfg := smsConfig{
-		AppKey:    resolveAppKey(appKeyFlag),
-		APISecret: resolveAPISecret(apiSecretFlag),
-		BaseURL:   strings.TrimRight(strings.TrimSpace(baseURLFlag), "/"),
+		BaseURL: strings.TrimRight(firstNonEmpty(baseURLFlag, p.SMSBaseURL, defaultSMSBaseURL), "/"),
+		Sender:  p.Sender,
+	}
+	if cfg.AppKey, err = resolveAppKey(appKeyFlag, p); err != nil {
+		return cfg, err
+	}
+	if cfg.APISecret, err = resolveAPISecret(apiSecretFlag, p); err != nil {
+		return cfg, err
 	}
 	if err := requireAuth(cfg.AppKey, cfg.APISecret); err != nil {
 		return cfg, err
@@ -32,37 +46,64 @@ func resolveSMSConfig(appKeyFlag, apiSecretFlag, baseURLFlag string) (smsConfig,
 	return cfg, nil
 }
 
+func (cfg smsConfig) client() *sms.Client {
+	return sms.NewClient(cfg.AppKey, cfg.APISecret, sms.WithBaseURL(cfg.BaseURL), sms.WithHTTPClient(httpClient), sms.WithRetry(retryPolicy()))
+}
+
 func runSMS(args []string) error {
 	if len(args) == 0 {
-		printSMSUsage()
-		return fmt.Errorf("مطلوب أمر فرعي لـ sms")
+		printSMSUsage(os.Stderr)
+		return usageErrorf("مطلوب أمر فرعي لـ sms")
 	}
 
 	switch args[0] {
 	case "send":
 		return runSMSSend(args[1:])
+	case "resume":
+		return runSMSResume(args[1:])
+	case "status":
+		return runSMSStatus(args[1:])
+	case "history":
+		return runSMSHistory(args[1:])
+	case "estimate":
+		return runSMSEstimate(args[1:])
 	case "balance":
 		return runSMSBalance(args[1:])
 	case "senders":
 		return runSMSSenders(args[1:])
 	case "help", "-h", "--help":
-		printSMSUsage()
+		printSMSUsage(os.Stdout)
 		return nil
 	default:
-		return fmt.Errorf("أمر sms غير معروف %q", args[0])
+		return usageErrorf("أمر sms غير معروف %q", args[0])
 	}
 }
 
 func runSMSSend(args []string) error {
 	fs := flag.NewFlagSet("sms send", flag.ContinueOnError)
 	appKeyFlag := fs.String("app-key", "", "مفتاح API")
-	apiSecretFlag := fs.String("api-secret", "", "سر API")
+	apiSecretFlag := fs.String("api-secret", "", "سر API (أو - للقراءة من stdin)")
+	addSecretFileFlag(fs)
 	senderFlag := fs.String("sender", "", "اسم المرسل المعتمد")
-	toFlag := fs.String("to", "", "أرقام مفصولة بفاصلة")
-	messageFlag := fs.String("message", "", "نص الرسالة")
-	baseURLFlag := fs.String("base-url", defaultSMSBaseURL, "رابط API")
-	dryRun := fs.Bool("dry-run", false, "معاينة بدون إرسال")
-	if err := fs.Parse(args); err != nil {
+	rf := addRecipientFlags(fs)
+	skipSenderCheck := fs.Bool("skip-sender-check", false, "عدم التحقق من اعتماد اسم المرسل قبل الإرسال")
+	requireBalanceFlag := fs.Bool("require-balance", false, "إيقاف الإرسال إذا كان الرصيد لا يكفي")
+	pointsPerSegment := fs.Float64("points-per-segment", 1, "النقاط المخصومة لكل جزء (مع --require-balance)")
+	opts := addBulkFlags(fs)
+	sf := addScheduleFlags(fs)
+	messageFlag := fs.String("message", "", "نص الرسالة، ويقبل متغيرات مثل {{.name}} من أعمدة الملف")
+	tf := addMessageTemplateFlags(fs)
+	baseURLFlag := fs.String("base-url", "", "رابط API (الافتراضي: "+defaultSMSBaseURL+")")
+	addGlobalFlags(fs)
+	addRetryFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	message, err := tf.load(trimFlag(messageFlag), channelSMS)
+	if err != nil {
 		return err
 	}
 
@@ -71,197 +112,231 @@ func runSMSSend(args []string) error {
 		return err
 	}
 
-	sender := firstNonEmpty(*senderFlag, envOrDefault("FOURJAWALY_SMS_SENDER", ""), envOrDefault("SMS_SENDER", ""))
-	to := trimFlag(toFlag)
-	message := trimFlag(messageFlag)
+	sender := firstNonEmpty(*senderFlag, envOrDefault("FOURJAWALY_SMS_SENDER", ""), envOrDefault("SMS_SENDER", ""), cfg.Sender)
 
-	if err := requireNonEmpty(sender, "--sender أو متغير البيئة FOURJAWALY_SMS_SENDER"); err != nil {
+	if err := requireNonEmpty(sender, "--sender أو متغير البيئة FOURJAWALY_SMS_SENDER أو sender في ملف التعريف"); err != nil {
 		return err
 	}
-	if err := requireNonEmpty(to, "--to"); err != nil {
+	if err := requireNonEmpty(trimFlag(&rf.To)+trimFlag(&rf.ToFile), "--to أو --to-file"); err != nil {
 		return err
 	}
-	if err := requireNonEmpty(message, "--message"); err != nil {
+	if err := requireNonEmpty(message, "--message أو --template"); err != nil {
 		return err
 	}
 
-	numbers := splitAndCleanCSV(to)
-	if len(numbers) == 0 {
-		return fmt.Errorf("قيمة --to غير صحيحة")
+	sendAt, err := sf.resolve(time.Now())
+	if err != nil {
+		return err
 	}
 
-	if len(numbers) > 100 {
-		return sendSMSChunked(cfg, message, numbers, sender, *dryRun)
+	recipients, duplicates, err := rf.load()
+	if err != nil {
+		return err
+	}
+	opts.DuplicatesRemoved = duplicates
+	if err := tf.apply(message, recipients); err != nil {
+		return err
 	}
 
-	payload := map[string]any{
-		"messages": []map[string]any{
-			{
-				"text":    message,
-				"numbers": numbers,
-				"sender":  sender,
-			},
-		},
+	messages, err := buildSMSMessages(message, sender, recipients)
+	if err != nil {
+		return err
+	}
+	if *requireBalanceFlag && *pointsPerSegment <= 0 {
+		return usageErrorf("--points-per-segment يجب أن يكون أكبر من صفر")
+	}
+	// A dry run stays offline unless --require-balance asks for the balance.
+	preflight := func() error {
+		if !*skipSenderCheck && !opts.DryRun {
+			warnUnapprovedSender(cfg, sender)
+		}
+		if *requireBalanceFlag {
+			return requireBalance(cfg, estimateMessages(messages), *pointsPerSegment, opts.DryRun)
+		}
+		return nil
+	}
+	if err := preflight(); err != nil {
+		return err
+	}
+	if !sendAt.IsZero() {
+		if sf.Mode == scheduleAPI {
+			scheduleMessages(messages, sendAt)
+			progressf("مجدولة عبر الـ API في %s\n", sendAt.Format(time.DateTime+" MST"))
+		} else if opts.DryRun {
+			progressf("[dry-run] سيتم الانتظار محليًا حتى %s ثم الإرسال\n", sendAt.Format(time.DateTime+" MST"))
+		} else if time.Until(sendAt) > 0 {
+			if err := waitUntil(sendAt); err != nil {
+				return err
+			}
+			// The balance or the sender may have changed while waiting.
+			if err := preflight(); err != nil {
+				return err
+			}
+		}
 	}
 
-	endpoint := cfg.BaseURL + "/account/area/sms/send"
+	numbers := countNumbers(messages)
+	if numbers > sms.MaxNumbersPerRequest {
+		return sendSMSChunked(cfg, messages, opts)
+	}
+
+	client := cfg.client()
+	payload := sms.SendRequest{Messages: messages}
 
-	if *dryRun {
-		return dryRunPrint(http.MethodPost, endpoint, payload)
+	if opts.DryRun {
+		return smsDryRun(http.MethodPost, client.SendURL(), payload, estimateMessages(messages))
 	}
 
-	body, err := json.Marshal(payload)
+	report, err := opts.createReport()
 	if err != nil {
 		return err
 	}
+	defer report.Close()
 
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	cr := sendSMSOneChunk(client, messages)
+	report.Write(cr)
+	res, err := cr.Result, cr.Error
 	if err != nil {
+		return reportAPIError(err)
+	}
+	if err := emitResult(res.Meta, sendResultTable(res, numbers)); err != nil {
 		return err
 	}
-	req.Header.Set("Authorization", basicAuthHeader(cfg.AppKey, cfg.APISecret))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	if classifyChunk(cr) == outcomeUnknown {
+		return &unknownResultError{Unknown: numbers, Total: numbers}
+	}
+	return nil
+}
 
-	resBody, status, err := doRequest(req)
+func runSMSResume(args []string) error {
+	fs := flag.NewFlagSet("sms resume", flag.ContinueOnError)
+	appKeyFlag := fs.String("app-key", "", "مفتاح API")
+	apiSecretFlag := fs.String("api-secret", "", "سر API (أو - للقراءة من stdin)")
+	addSecretFileFlag(fs)
+	baseURLFlag := fs.String("base-url", "", "رابط API (الافتراضي: المسجل في ملف التتبع)")
+	resendUncertain := fs.Bool("resend-uncertain", false, "إعادة المجموعات غير المؤكدة أيضًا (قد تصل مرتين)")
+	opts := addBulkFlags(fs)
+	addGlobalFlags(fs)
+	addRetryFlags(fs)
+	positional, err := parseFlagsWithArgs(fs, args)
 	if err != nil {
 		return err
 	}
-	return printResponse(resBody, status)
-}
-
-type chunkResult struct {
-	StatusCode int
-	Response   map[string]any
-	Numbers    []string
-	Error      error
-}
-
-func sendSMSChunked(cfg smsConfig, message string, numbers []string, sender string, dryRun bool) error {
-	chunkSize := 100
-	chunks := chunkSlice(numbers, chunkSize)
-
-	fmt.Printf("إرسال مجمّع: %d رقم في %d مجموعة...\n", len(numbers), len(chunks))
-
-	if dryRun {
-		fmt.Println("[dry-run] لن يتم الإرسال الفعلي")
-		fmt.Printf("[dry-run] %d مجموعة × حتى %d رقم\n", len(chunks), chunkSize)
-		return nil
+	if err := opts.validate(); err != nil {
+		return err
 	}
 
-	resultsChan := make(chan chunkResult, len(chunks))
-	var wg sync.WaitGroup
-
-	for _, chunk := range chunks {
-		wg.Add(1)
-		go func(nums []string) {
-			defer wg.Done()
-			resultsChan <- sendSMSOneChunk(cfg, message, nums, sender)
-		}(chunk)
+	path := opts.JournalPath
+	switch {
+	case len(positional) > 1, len(positional) == 1 && path != "":
+		return usageErrorf("sms resume يقبل ملف تتبع واحد فقط")
+	case len(positional) == 1:
+		path = positional[0]
+	case path == "":
+		return usageErrorf("مطلوب ملف التتبع: 4jawaly-cli sms resume <ملف>")
 	}
 
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
+	st, err := readJournal(path)
+	if err != nil {
+		return err
+	}
 
-	totalSuccess := 0
-	totalFailed := 0
-	var jobIDs []string
+	cfg, err := resolveSMSConfig(*appKeyFlag, *apiSecretFlag, firstNonEmpty(*baseURLFlag, st.Campaign.BaseURL))
+	if err != nil {
+		return err
+	}
 
-	for cr := range resultsChan {
-		if cr.Error != nil {
-			totalFailed += len(cr.Numbers)
-			fmt.Fprintf(os.Stderr, "خطأ في مجموعة (%d أرقام): %v\n", len(cr.Numbers), cr.Error)
+	// Uncertain chunks may already have been delivered and charged, so they
+	// are only sent again when asked for.
+	var pending []smsChunk
+	previous, uncertain, skippedNumbers := 0, 0, 0
+	for _, c := range st.Chunks {
+		if _, ok := st.Sent[c.Index]; ok {
+			previous += countNumbers(c.Messages)
 			continue
 		}
-		if cr.StatusCode == http.StatusOK {
-			if msgs, ok := cr.Response["messages"].([]any); ok && len(msgs) > 0 {
-				if errText, ok := msgs[0].(map[string]any)["err_text"]; ok {
-					totalFailed += len(cr.Numbers)
-					fmt.Fprintf(os.Stderr, "خطأ API: %v\n", errText)
-				} else {
-					totalSuccess += len(cr.Numbers)
-					if jid, ok := cr.Response["job_id"].(string); ok {
-						jobIDs = append(jobIDs, jid)
-					}
-				}
+		if st.Uncertain[c.Index] {
+			uncertain++
+			if !*resendUncertain {
+				skippedNumbers += countNumbers(c.Messages)
+				continue
 			}
-		} else {
-			totalFailed += len(cr.Numbers)
-			fmt.Fprintf(os.Stderr, "خطأ HTTP %d لمجموعة %d أرقام\n", cr.StatusCode, len(cr.Numbers))
 		}
+		pending = append(pending, c)
 	}
 
-	summary := map[string]any{
-		"نجح":      totalSuccess,
-		"فشل":      totalFailed,
-		"الإجمالي": len(numbers),
-		"job_ids":  jobIDs,
-	}
-	return prettyPrintJSON(summary)
-}
-
-func sendSMSOneChunk(cfg smsConfig, message string, numbers []string, sender string) chunkResult {
-	payload := map[string]any{
-		"messages": []map[string]any{
-			{
-				"text":    message,
-				"numbers": numbers,
-				"sender":  sender,
-			},
-		},
+	progressf("استئناف: %d من %d مجموعة مكتملة، %d متبقية\n", len(st.Sent), len(st.Chunks), len(pending))
+	if uncertain > 0 {
+		if *resendUncertain {
+			progressf("تحذير: %d مجموعة غير مؤكدة ستُعاد وقد تصل مرتين\n", uncertain)
+		} else {
+			progressf("تم تخطي %d مجموعة غير مؤكدة قد تكون وصلت (راجعها بـ sms status، أو أعدها بـ --resend-uncertain)\n", uncertain)
+		}
 	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return chunkResult{Error: err, Numbers: numbers}
+	if opts.DryRun || len(pending) == 0 {
+		pendingNumbers := 0
+		for _, c := range pending {
+			pendingNumbers += countNumbers(c.Messages)
+		}
+		err := emitValue(map[string]any{
+			"dry_run":   opts.DryRun,
+			"journal":   path,
+			"chunks":    len(st.Chunks),
+			"sent":      len(st.Sent),
+			"pending":   len(pending),
+			"uncertain": uncertain,
+			"numbers":   pendingNumbers,
+		}, nil)
+		if err != nil || opts.DryRun || skippedNumbers == 0 {
+			return err
+		}
+		return &unknownResultError{Unknown: skippedNumbers, Total: st.Campaign.Numbers}
 	}
 
-	endpoint := cfg.BaseURL + "/account/area/sms/send"
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
-	if err != nil {
-		return chunkResult{Error: err, Numbers: numbers}
+	var journal *journalWriter
+	if !opts.NoJournal {
+		if journal, err = appendJournal(path); err != nil {
+			return err
+		}
+		defer journal.Close()
 	}
-	req.Header.Set("Authorization", basicAuthHeader(cfg.AppKey, cfg.APISecret))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
 
-	resBody, status, err := doRequest(req)
+	report, err := opts.createReport()
 	if err != nil {
-		return chunkResult{Error: err, Numbers: numbers}
-	}
-
-	var response map[string]any
-	if err := json.Unmarshal(resBody, &response); err != nil {
-		return chunkResult{Error: err, Numbers: numbers}
-	}
-
-	return chunkResult{
-		StatusCode: status,
-		Response:   response,
-		Numbers:    numbers,
+		return err
 	}
-}
+	defer report.Close()
 
-func chunkSlice(slice []string, size int) [][]string {
-	var chunks [][]string
-	for i := 0; i < len(slice); i += size {
-		end := i + size
-		if end > len(slice) {
-			end = len(slice)
+	totals := runSMSChunks(cfg, pending, opts, journal, report)
+	if skippedNumbers > 0 {
+		if totals.Outcomes == nil {
+			totals.Outcomes = map[string]int{}
 		}
-		chunks = append(chunks, slice[i:end])
+		totals.Unknown += skippedNumbers
+		totals.Outcomes[outcomeUnknown.String()] += skippedNumbers
 	}
-	return chunks
+	return finishBulk(totals, st.Campaign.Numbers, previous, opts, journal)
 }
 
 func runSMSBalance(args []string) error {
 	fs := flag.NewFlagSet("sms balance", flag.ContinueOnError)
 	appKeyFlag := fs.String("app-key", "", "مفتاح API")
-	apiSecretFlag := fs.String("api-secret", "", "سر API")
-	baseURLFlag := fs.String("base-url", defaultSMSBaseURL, "رابط API")
-	if err := fs.Parse(args); err != nil {
+	apiSecretFlag := fs.String("api-secret", "", "سر API (أو - للقراءة من stdin)")
+	addSecretFileFlag(fs)
+	pf := addPageFlags(fs, 10)
+	isActiveFlag := fs.String("is-active", "1", "1 للباقات الفعالة، 0 لغير الفعالة، all للكل")
+	baseURLFlag := fs.String("base-url", "", "رابط API (الافتراضي: "+defaultSMSBaseURL+")")
+	addGlobalFlags(fs)
+	addRetryFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := pf.validate(fs); err != nil {
+		return err
+	}
+	isActive, err := parseIntFilter("--is-active", *isActiveFlag)
+	if err != nil {
 		return err
 	}
 
@@ -270,36 +345,72 @@ func runSMSBalance(args []string) error {
 		return err
 	}
 
-	query := url.Values{}
-	query.Set("is_active", "1")
-	query.Set("order_by", "id")
-	query.Set("order_by_type", "desc")
-	query.Set("page", "1")
-	query.Set("page_size", "10")
-	query.Set("return_collection", "1")
+	q := sms.PackagesQuery{Page: pf.Page, PageSize: pf.PageSize}
+	if isActive != nil {
+		active := *isActive != 0
+		q.IsActive = &active
+	}
+	client := cfg.client()
+	ctx := context.Background()
 
-	endpoint := cfg.BaseURL + "/account/area/me/packages?" + query.Encode()
-	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
-	if err != nil {
-		return err
+	if !pf.All {
+		res, err := client.ListPackages(ctx, q)
+		if err != nil {
+			return reportAPIError(err)
+		}
+		warnMorePages(res.Packages)
+		return emitResult(res.Meta, packagesTable(res))
 	}
-	req.Header.Set("Authorization", basicAuthHeader(cfg.AppKey, cfg.APISecret))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
 
-	resBody, status, err := doRequest(req)
+	all := &sms.PackagesResult{}
+	items, err := sms.NewPager(func(ctx context.Context, page int) (sms.Collection[sms.Package], error) {
+		q.Page = page
+		res, err := client.ListPackages(ctx, q)
+		if err != nil {
+			return sms.Collection[sms.Package]{}, err
+		}
+		all.TotalBalance = res.TotalBalance
+		return res.Packages, nil
+	}).All(ctx)
 	if err != nil {
-		return err
+		return reportAPIError(err)
 	}
-	return printResponse(resBody, status)
+	all.Packages.Data = items
+	return emitValue(map[string]any{
+		"total_balance": all.TotalBalance,
+		"packages":      nonNil(items),
+	}, packagesTable(all))
 }
 
 func runSMSSenders(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "request":
+			return runSMSSendersRequest(args[1:])
+		case "show":
+			return runSMSSendersShow(args[1:])
+		case "list":
+			args = args[1:]
+		}
+	}
+
 	fs := flag.NewFlagSet("sms senders", flag.ContinueOnError)
 	appKeyFlag := fs.String("app-key", "", "مفتاح API")
-	apiSecretFlag := fs.String("api-secret", "", "سر API")
-	baseURLFlag := fs.String("base-url", defaultSMSBaseURL, "رابط API")
-	if err := fs.Parse(args); err != nil {
+	apiSecretFlag := fs.String("api-secret", "", "سر API (أو - للقراءة من stdin)")
+	addSecretFileFlag(fs)
+	pf := addPageFlags(fs, 50)
+	statusFlag := fs.String("status", "1", "حالة المرسل (1 معتمد) أو all للكل")
+	baseURLFlag := fs.String("base-url", "", "رابط API (الافتراضي: "+defaultSMSBaseURL+")")
+	addGlobalFlags(fs)
+	addRetryFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := pf.validate(fs); err != nil {
+		return err
+	}
+	status, err := parseIntFilter("--status", *statusFlag)
+	if err != nil {
 		return err
 	}
 
@@ -308,42 +419,128 @@ func runSMSSenders(args []string) error {
 		return err
 	}
 
-	query := url.Values{}
-	query.Set("page_size", "50")
-	query.Set("page", "1")
-	query.Set("status", "1")
-	query.Set("return_collection", "1")
+	q := sms.SendersQuery{Status: status, Page: pf.Page, PageSize: pf.PageSize}
+	client := cfg.client()
+	ctx := context.Background()
 
-	endpoint := cfg.BaseURL + "/account/area/senders?" + query.Encode()
-	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
-	if err != nil {
-		return err
+	if !pf.All {
+		res, err := client.ListSenders(ctx, q)
+		if err != nil {
+			return reportAPIError(err)
+		}
+		warnMorePages(res.Senders)
+		return emitResult(res.Meta, sendersTable(res))
 	}
-	req.Header.Set("Authorization", basicAuthHeader(cfg.AppKey, cfg.APISecret))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
 
-	resBody, status, err := doRequest(req)
+	items, err := sms.NewPager(func(ctx context.Context, page int) (sms.Collection[sms.Sender], error) {
+		q.Page = page
+		res, err := client.ListSenders(ctx, q)
+		if err != nil {
+			return sms.Collection[sms.Sender]{}, err
+		}
+		return res.Senders, nil
+	}).All(ctx)
 	if err != nil {
-		return err
+		return reportAPIError(err)
+	}
+	all := &sms.SendersResult{}
+	all.Senders.Data = items
+	return emitValue(map[string]any{"senders": nonNil(items)}, sendersTable(all))
+}
+
+func sendResultTable(res *sms.SendResult, numbers int) *table {
+	return &table{
+		header: []string{"job_id", "numbers", "message"},
+		rows:   [][]string{{res.JobID.String(), strconv.Itoa(numbers), res.Message}},
 	}
-	return printResponse(resBody, status)
 }
 
-func printSMSUsage() {
-	fmt.Println("أوامر SMS:")
-	fmt.Println("")
-	fmt.Println("  4jawaly-cli sms send \\")
-	fmt.Println("    --to \"9665XXXXXXXX,9665YYYYYYYY\" \\")
-	fmt.Println("    --message \"نص الرسالة\" \\")
-	fmt.Println("    --sender \"اسم المرسل\"")
-	fmt.Println("")
-	fmt.Println("  4jawaly-cli sms balance")
-	fmt.Println("  4jawaly-cli sms senders")
-	fmt.Println("")
-	fmt.Println("خيارات:")
-	fmt.Println("  --app-key      مفتاح API (أو FOURJAWALY_APP_KEY)")
-	fmt.Println("  --api-secret   سر API (أو FOURJAWALY_API_SECRET)")
-	fmt.Println("  --sender       اسم المرسل (أو FOURJAWALY_SMS_SENDER)")
-	fmt.Println("  --dry-run      معاينة بدون إرسال فعلي")
+func packagesTable(res *sms.PackagesResult) *table {
+	t := &table{header: []string{"id", "package_points", "current_points", "expire_at", "is_active"}}
+	for _, p := range res.Packages.Data {
+		t.rows = append(t.rows, []string{
+			formatInt(p.ID),
+			formatFloat(p.PackagePoints),
+			formatFloat(p.CurrentPoints),
+			p.ExpireAt,
+			formatInt(p.IsActive),
+		})
+	}
+	return t
+}
+
+func sendersTable(res *sms.SendersResult) *table {
+	t := &table{header: []string{"id", "sender_name", "status", "is_default", "note"}}
+	for _, snd := range res.Senders.Data {
+		t.rows = append(t.rows, []string{
+			formatInt(snd.ID),
+			snd.SenderName,
+			formatInt(snd.Status),
+			formatInt(snd.IsDefault),
+			snd.Note,
+		})
+	}
+	return t
+}
+
+func printSMSUsage(w io.Writer) {
+	fmt.Fprintln(w, "أوامر SMS:")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "  4jawaly-cli sms send \\")
+	fmt.Fprintln(w, "    --to \"9665XXXXXXXX,9665YYYYYYYY\" \\")
+	fmt.Fprintln(w, "    --message \"نص الرسالة\" \\")
+	fmt.Fprintln(w, "    --sender \"اسم المرسل\"")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "  4jawaly-cli sms send \\")
+	fmt.Fprintln(w, "    --to-file customers.csv \\")
+	fmt.Fprintln(w, "    --message \"مرحبا {{.name}}، رصيدك {{.points}}\" \\")
+	fmt.Fprintln(w, "    --sender \"اسم المرسل\"")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "  4jawaly-cli sms resume <ملف-journal> [--resend-uncertain]")
+	fmt.Fprintln(w, "  4jawaly-cli sms status --job-id <job_id> [--watch]")
+	fmt.Fprintln(w, "  4jawaly-cli sms status --from-report report.csv")
+	fmt.Fprintln(w, "  4jawaly-cli sms history --since 2026-10-01 --until 2026-10-18 [--sender ...] [--number ...] [--status failed] [--export out.csv]")
+	fmt.Fprintln(w, "  4jawaly-cli sms estimate --message \"نص الرسالة\" [--to-file customers.csv | --recipients 5000] [--check-balance]")
+	fmt.Fprintln(w, "  4jawaly-cli sms balance")
+	fmt.Fprintln(w, "  4jawaly-cli sms senders")
+	fmt.Fprintln(w, "  4jawaly-cli sms senders request --name \"MyBrand\" --reason \"إشعارات العملاء\"")
+	fmt.Fprintln(w, "  4jawaly-cli sms senders show MyBrand")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "خيارات:")
+	fmt.Fprintln(w, "  --app-key      مفتاح API (أو FOURJAWALY_APP_KEY)")
+	fmt.Fprintln(w, "  --api-secret   سر API (أو FOURJAWALY_API_SECRET)؛ - للقراءة من stdin")
+	fmt.Fprintln(w, "  --api-secret-file  ملف يحتوي السر (أو FOURJAWALY_API_SECRET_FILE)")
+	fmt.Fprintln(w, "  --sender       اسم المرسل (أو FOURJAWALY_SMS_SENDER أو sender في ملف التعريف)")
+	fmt.Fprintln(w, "  --to-file      ملف أرقام txt/csv/xlsx (أعمدته متاحة في --message)")
+	fmt.Fprintln(w, "  --template     قالب محفوظ بدل --message (راجع: template list)، مع --var key=value")
+	fmt.Fprintln(w, "  --phone-column اسم عمود الرقم في csv/xlsx")
+	fmt.Fprintln(w, "  --country      الدولة للأرقام المحلية مثل 05XXXXXXXX (أو FOURJAWALY_DEFAULT_COUNTRY، الافتراضي SA)")
+	fmt.Fprintln(w, "  --skip-invalid تجاهل الأرقام غير الصحيحة بدل إيقاف الإرسال")
+	fmt.Fprintln(w, "  --send-at      موعد الإرسال مثل 2026-10-20T09:00، مع --tz Asia/Riyadh")
+	fmt.Fprintln(w, "  --schedule-mode api (الجدولة عبر الـ API، الافتراضي) أو wait (الانتظار محليًا)")
+	fmt.Fprintln(w, "  --require-balance  إيقاف الإرسال إذا كانت الأجزاء × --points-per-segment أكثر من الرصيد")
+	fmt.Fprintln(w, "  --skip-sender-check  عدم التحقق من اعتماد --sender قبل الإرسال")
+	fmt.Fprintln(w, "  --allow-duplicates  إرسال الرقم المكرر أكثر من مرة (الافتراضي حذف التكرار)")
+	fmt.Fprintln(w, "  --concurrency  عدد الطلبات المتزامنة في الإرسال المجمّع (الافتراضي 4)")
+	fmt.Fprintln(w, "  --rate         أقصى طلبات في الثانية في الإرسال المجمّع (الافتراضي 10، و 0 بلا حد)")
+	fmt.Fprintln(w, "  --journal      ملف تتبع الإرسال المجمّع للاستئناف (الافتراضي تلقائي)")
+	fmt.Fprintln(w, "  --no-journal   تعطيل ملف التتبع")
+	fmt.Fprintln(w, "  --report       تقرير لكل رقم (csv أو jsonl حسب الامتداد): المجموعة، حالة HTTP، job_id، err_text")
+	fmt.Fprintln(w, "  --dry-run      معاينة بدون إرسال فعلي")
+	fmt.Fprintln(w, "  --all          (balance/senders) جلب كل الصفحات؛ أو --page و --page-size لصفحة واحدة")
+	fmt.Fprintln(w, "  --is-active    (balance) 1 أو 0 أو all (الافتراضي 1)")
+	fmt.Fprintln(w, "  --status       (senders) حالة المرسل أو all (الافتراضي 1)")
+	fmt.Fprintln(w, "  --recipients   (estimate) عدد المستلمين بدل --to/--to-file")
+	fmt.Fprintln(w, "  --check-balance (estimate) مقارنة عدد الأجزاء × --points-per-segment (الافتراضي 1) بالرصيد")
+	fmt.Fprintln(w, "  --limit        (history) أقصى عدد رسائل (الافتراضي 1000، و 0 للكل)")
+	fmt.Fprintln(w, "  --max-pages    (history) أقصى عدد صفحات يُبحث فيها مع --status (الافتراضي 20، و 0 بلا حد)")
+	fmt.Fprintln(w, "  --export       (history) حفظ النتائج في ملف csv أو json حسب الامتداد")
+	fmt.Fprintln(w, "  --job-id       (status) job_id أو أكثر مفصولة بفاصلة")
+	fmt.Fprintln(w, "  --from-report  (status) قراءة job_id من ملف --report")
+	fmt.Fprintln(w, "  --watch        (status) إعادة الاستعلام كل --interval (الافتراضي 30s) حتى حالة نهائية أو --timeout (الافتراضي 30m)")
+	fmt.Fprintln(w, "  --output       صيغة المخرجات: text|json|table|csv|quiet")
+	fmt.Fprintln(w, "  --profile      ملف التعريف من ملف الإعدادات (أو FOURJAWALY_PROFILE)")
+	fmt.Fprintln(w, "  --retries      إعادة المحاولة عند أخطاء الشبكة و 429 و 5xx (الافتراضي 2)")
+	fmt.Fprintln(w, "  --retry-sends  إعادة الإرسال أيضًا بعد 5xx أو انقطاع قد يكون بعد وصول الطلب (قد يكرر الرسالة)")
+	fmt.Fprintln(w, "  --retry-delay  الانتظار قبل أول إعادة محاولة (الافتراضي 500ms)")
 }
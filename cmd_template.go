@@ -0,0 +1,254 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+func runTemplate(args []string) error {
+	if len(args) == 0 {
+		printTemplateUsage(os.Stderr)
+		return usageErrorf("مطلوب أمر فرعي لـ template")
+	}
+
+	switch args[0] {
+	case "add":
+		return runTemplateAdd(args[1:])
+	case "list":
+		return runTemplateList(args[1:])
+	case "show":
+		return runTemplateShow(args[1:])
+	case "rm":
+		return runTemplateRm(args[1:])
+	case "help", "-h", "--help":
+		printTemplateUsage(os.Stdout)
+		return nil
+	default:
+		return usageErrorf("أمر template غير معروف %q", args[0])
+	}
+}
+
+func runTemplateAdd(args []string) error {
+	fs := flag.NewFlagSet("template add", flag.ContinueOnError)
+	textFlag := fs.String("text", "", "نص القالب، ويقبل متغيرات مثل {{.code}}")
+	fileFlag := fs.String("file", "", "قراءة نص القالب من ملف بدل --text")
+	channelFlag := fs.String("channel", channelAll, "القناة: sms|wa|all")
+	descriptionFlag := fs.String("description", "", "وصف قصير")
+	force := fs.Bool("force", false, "استبدال القالب إن كان موجودًا")
+	addGlobalFlags(fs)
+	positional, err := parseFlagsWithArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf("الاستخدام: 4jawaly-cli template add <الاسم> --text <نص>")
+	}
+	name := positional[0]
+	if !templateNamePattern.MatchString(name) {
+		return usageErrorf("اسم القالب %q غير صحيح: حروف لاتينية وأرقام و _ . - فقط", name)
+	}
+	switch *channelFlag {
+	case channelSMS, channelWA, channelAll:
+	default:
+		return usageErrorf("--channel غير معروف %q (المتاح: sms, wa, all)", *channelFlag)
+	}
+
+	text := trimFlag(textFlag)
+	if path := trimFlag(fileFlag); path != "" {
+		if text != "" {
+			return usageErrorf("--text و --file لا يجتمعان")
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return validationErrorf("تعذر قراءة --file: %v", err)
+		}
+		text = strings.TrimSpace(string(data))
+	}
+	if err := requireNonEmpty(text, "--text أو --file"); err != nil {
+		return err
+	}
+	tmpl, err := parseMessageTemplate(name, text)
+	if err != nil {
+		return validationErrorf("قالب غير صحيح: %v", err)
+	}
+
+	store, err := newTemplateStore()
+	if err != nil {
+		return err
+	}
+	all, err := store.read()
+	if err != nil {
+		return err
+	}
+	if _, exists := all[name]; exists && !*force {
+		return validationErrorf("القالب %q موجود مسبقًا، استخدم --force لاستبداله", name)
+	}
+	t := savedTemplate{
+		Name:        name,
+		Channel:     *channelFlag,
+		Text:        text,
+		Description: trimFlag(descriptionFlag),
+		UpdatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	all[name] = t
+	if err := store.write(all); err != nil {
+		return err
+	}
+
+	progressf("تم حفظ القالب %q\n", name)
+	required, optional := templateFields(tmpl)
+	return emitTemplate(t, required, optional)
+}
+
+func runTemplateList(args []string) error {
+	fs := flag.NewFlagSet("template list", flag.ContinueOnError)
+	channelFlag := fs.String("channel", "", "عرض قوالب قناة واحدة: sms|wa")
+	addGlobalFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	channel := trimFlag(channelFlag)
+	switch channel {
+	case "", channelSMS, channelWA:
+	default:
+		return usageErrorf("--channel غير معروف %q (المتاح: sms, wa)", channel)
+	}
+
+	store, err := newTemplateStore()
+	if err != nil {
+		return err
+	}
+	templates, err := store.list()
+	if err != nil {
+		return err
+	}
+
+	type listed struct {
+		savedTemplate
+		Variables []string `json:"variables"`
+		Optional  []string `json:"optional_variables"`
+	}
+	items := []listed{}
+	t := &table{header: []string{"name", "channel", "variables", "optional", "description", "preview"}}
+	for _, st := range templates {
+		if channel != "" && !st.For(channel) {
+			continue
+		}
+		var required, optional []string
+		if tmpl, err := parseMessageTemplate(st.Name, st.Text); err == nil {
+			required, optional = templateFields(tmpl)
+		}
+		items = append(items, listed{st, nonNil(required), nonNil(optional)})
+		t.rows = append(t.rows, []string{st.Name, st.Channel, strings.Join(required, ","), strings.Join(optional, ","), st.Description, previewText(st.Text, 40)})
+	}
+	if len(items) == 0 {
+		progressf("لا توجد قوالب محفوظة (أضف واحدًا: 4jawaly-cli template add <الاسم> --text <نص>)\n")
+	}
+	return emitValue(items, t)
+}
+
+func runTemplateShow(args []string) error {
+	fs := flag.NewFlagSet("template show", flag.ContinueOnError)
+	addGlobalFlags(fs)
+	positional, err := parseFlagsWithArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf("الاستخدام: 4jawaly-cli template show <الاسم>")
+	}
+
+	store, err := newTemplateStore()
+	if err != nil {
+		return err
+	}
+	t, err := store.get(positional[0])
+	if err != nil {
+		return err
+	}
+	var required, optional []string
+	if tmpl, err := parseMessageTemplate(t.Name, t.Text); err == nil {
+		required, optional = templateFields(tmpl)
+	}
+	if output == outputText {
+		fmt.Println(t.Text)
+		progressf("القناة: %s، المتغيرات: %s\n", t.Channel, strings.Join(required, ", "))
+		if len(optional) > 0 {
+			progressf("متغيرات اختيارية: %s\n", strings.Join(optional, ", "))
+		}
+		return nil
+	}
+	return emitTemplate(t, required, optional)
+}
+
+func runTemplateRm(args []string) error {
+	fs := flag.NewFlagSet("template rm", flag.ContinueOnError)
+	addGlobalFlags(fs)
+	positional, err := parseFlagsWithArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usageErrorf("الاستخدام: 4jawaly-cli template rm <الاسم>...")
+	}
+
+	store, err := newTemplateStore()
+	if err != nil {
+		return err
+	}
+	all, err := store.read()
+	if err != nil {
+		return err
+	}
+	for _, name := range positional {
+		if _, ok := all[name]; !ok {
+			return validationErrorf("القالب %q غير موجود", name)
+		}
+		delete(all, name)
+	}
+	if err := store.write(all); err != nil {
+		return err
+	}
+	progressf("تم حذف %s\n", strings.Join(positional, ", "))
+	return emitValue(map[string]any{"removed": positional}, &table{
+		header: []string{"removed"},
+		rows:   [][]string{{strings.Join(positional, ",")}},
+	})
+}
+
+func emitTemplate(t savedTemplate, required, optional []string) error {
+	return emitValue(map[string]any{
+		"name":               t.Name,
+		"channel":            t.Channel,
+		"text":               t.Text,
+		"description":        t.Description,
+		"variables":          nonNil(required),
+		"optional_variables": nonNil(optional),
+		"updated_at":         t.UpdatedAt,
+	}, &table{
+		header: []string{"name", "channel", "variables", "optional", "description", "text"},
+		rows:   [][]string{{t.Name, t.Channel, strings.Join(required, ","), strings.Join(optional, ","), t.Description, t.Text}},
+	})
+}
+
+func printTemplateUsage(w io.Writer) {
+	fmt.Fprintln(w, "أوامر template:")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "  4jawaly-cli template add otp --text \"رمز التحقق: {{.code}}\" [--channel sms|wa|all] [--description ..]")
+	fmt.Fprintln(w, "  4jawaly-cli template add invoice --file invoice.txt --channel sms --force")
+	fmt.Fprintln(w, "  4jawaly-cli template list [--channel sms|wa]")
+	fmt.Fprintln(w, "  4jawaly-cli template show otp")
+	fmt.Fprintln(w, "  4jawaly-cli template rm otp")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "الاستخدام في الإرسال:")
+	fmt.Fprintln(w, "  4jawaly-cli sms send --to 9665XXXXXXXX --template otp --var code=1234")
+	fmt.Fprintln(w, "  4jawaly-cli wa send-text --to 9665XXXXXXXX --template otp --var code=1234")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "تُحفظ القوالب في ~/.config/4jawaly/templates.json (أو FOURJAWALY_TEMPLATES_FILE).")
+	fmt.Fprintln(w, "يجب أن تُعبّأ كل المتغيرات من --var أو من أعمدة --to-file، وإلا يتوقف الإرسال.")
+	fmt.Fprintln(w, "المتغيرات داخل {{if}} أو {{with}} أو {{range}} فقط اختيارية وتُعامل كفارغة إن لم تُمرر.")
+}
@@ -2,15 +2,21 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
+
+	"fourjawaly-cli/fourjawaly/phone"
+	"fourjawaly-cli/fourjawaly/whatsapp"
 )
 
-const defaultWABaseURL = "https://api-users.4jawaly.com/api/v1/whatsapp"
+const defaultWABaseURL = whatsapp.DefaultBaseURL
 
 type waConfig struct {
 	AppKey    string
@@ -20,26 +26,38 @@ type waConfig struct {
 }
 
 func resolveWAConfig(appKeyFlag, apiSecretFlag, projectIDFlag, baseURLFlag string) (waConfig, error) {
+	p, err := activeProfile()
+	if err != nil {
+		return waConfig{}, err
+	}
 	cfg := waConfig{
-		AppKey:    resolveAppKey(appKeyFlag),
-		APISecret: resolveAPISecret(apiSecretFlag),
-		ProjectID: firstNonEmpty(projectIDFlag, envOrDefault("FOURJAWALY_WHATSAPP_PROJECT_ID", ""), envOrDefault("PROJECT_ID", "")),
-		BaseURL:   strings.TrimRight(strings.TrimSpace(baseURLFlag), "/"),
+		ProjectID: firstNonEmpty(projectIDFlag, envOrDefault("FOURJAWALY_WHATSAPP_PROJECT_ID", ""), envOrDefault("PROJECT_ID", ""), p.ProjectID),
+		BaseURL:   strings.TrimRight(firstNonEmpty(baseURLFlag, p.WABaseURL, defaultWABaseURL), "/"),
 	}
 
+	if cfg.AppKey, err = resolveAppKey(appKeyFlag, p); err != nil {
+		return cfg, err
+	}
+	if cfg.APISecret, err = resolveAPISecret(apiSecretFlag, p); err != nil {
+		return cfg, err
+	}
 	if err := requireAuth(cfg.AppKey, cfg.APISecret); err != nil {
 		return cfg, err
 	}
 	if cfg.ProjectID == "" {
-		return cfg, fmt.Errorf("مطلوب project-id (عبر --project-id أو متغير البيئة FOURJAWALY_WHATSAPP_PROJECT_ID)")
+		return cfg, &authError{msg: "مطلوب project-id (عبر --project-id أو متغير البيئة FOURJAWALY_WHATSAPP_PROJECT_ID أو project_id في ملف التعريف)"}
 	}
 	return cfg, nil
 }
 
+func (cfg waConfig) client() *whatsapp.Client {
+	return whatsapp.NewClient(cfg.AppKey, cfg.APISecret, cfg.ProjectID, whatsapp.WithBaseURL(cfg.BaseURL), whatsapp.WithHTTPClient(httpClient), whatsapp.WithRetry(retryPolicy()))
+}
+
 func runWhatsApp(args []string) error {
 	if len(args) == 0 {
-		printWAUsage()
-		return fmt.Errorf("مطلوب أمر فرعي لـ wa")
+		printWAUsage(os.Stderr)
+		return usageErrorf("مطلوب أمر فرعي لـ wa")
 	}
 
 	switch args[0] {
@@ -61,11 +79,13 @@ func runWhatsApp(args []string) error {
 		return runWASendLocation(args[1:])
 	case "send-contact":
 		return runWASendContact(args[1:])
+	case "send-template":
+		return runWASendTemplate(args[1:])
 	case "help", "-h", "--help":
-		printWAUsage()
+		printWAUsage(os.Stdout)
 		return nil
 	default:
-		return fmt.Errorf("أمر wa غير معروف %q", args[0])
+		return usageErrorf("أمر wa غير معروف %q", args[0])
 	}
 }
 
@@ -73,26 +93,37 @@ func runWhatsApp(args []string) error {
 
 func waBaseFlags(fs *flag.FlagSet) (*string, *string, *string, *string, *string, *bool) {
 	appKey := fs.String("app-key", "", "مفتاح API")
-	apiSecret := fs.String("api-secret", "", "سر API")
+	apiSecret := fs.String("api-secret", "", "سر API (أو - للقراءة من stdin)")
+	addSecretFileFlag(fs)
 	projectID := fs.String("project-id", "", "رقم مشروع واتساب")
 	to := fs.String("to", "", "رقم المستلم")
-	baseURL := fs.String("base-url", defaultWABaseURL, "رابط API")
+	fs.String("country", "", "الدولة الافتراضية للأرقام المحلية (الافتراضي: SA)")
+	baseURL := fs.String("base-url", "", "رابط API (الافتراضي: "+defaultWABaseURL+")")
 	dryRun := fs.Bool("dry-run", false, "معاينة بدون إرسال")
+	addGlobalFlags(fs)
+	addRetryFlags(fs)
 	return appKey, apiSecret, projectID, to, baseURL, dryRun
 }
 
 func parseWAFlags(fs *flag.FlagSet, args []string, appKey, apiSecret, projectID, to, baseURL *string) (waConfig, string, error) {
-	if err := fs.Parse(args); err != nil {
+	if err := parseFlags(fs, args); err != nil {
 		return waConfig{}, "", err
 	}
 	cfg, err := resolveWAConfig(*appKey, *apiSecret, *projectID, *baseURL)
 	if err != nil {
 		return waConfig{}, "", err
 	}
-	recipient := trimFlag(to)
-	if err := requireNonEmpty(recipient, "--to"); err != nil {
+	if err := requireNonEmpty(trimFlag(to), "--to"); err != nil {
 		return waConfig{}, "", err
 	}
+	country, err := resolveCountry(fs.Lookup("country").Value.String())
+	if err != nil {
+		return waConfig{}, "", err
+	}
+	recipient, err := phone.Normalize(trimFlag(to), country)
+	if err != nil {
+		return waConfig{}, "", &validationError{msg: err.Error()}
+	}
 	return cfg, recipient, nil
 }
 
@@ -102,21 +133,26 @@ func runWASendText(args []string) error {
 	fs := flag.NewFlagSet("wa send-text", flag.ContinueOnError)
 	appKey, apiSecret, projectID, to, baseURL, dryRun := waBaseFlags(fs)
 	messageFlag := fs.String("message", "", "نص الرسالة")
+	tf := addMessageTemplateFlags(fs)
 
 	cfg, recipient, err := parseWAFlags(fs, args, appKey, apiSecret, projectID, to, baseURL)
 	if err != nil {
 		return err
 	}
-	message := trimFlag(messageFlag)
-	if err := requireNonEmpty(message, "--message"); err != nil {
+	message, err := tf.load(trimFlag(messageFlag), channelWA)
+	if err != nil {
 		return err
 	}
-
-	data := map[string]any{
-		"type": "text",
-		"text": map[string]string{"body": message},
+	if err := requireNonEmpty(message, "--message أو --template"); err != nil {
+		return err
 	}
-	return sendWARequest(cfg, recipient, data, *dryRun)
+	if tf.Name != "" {
+		if message, err = tf.render(message); err != nil {
+			return err
+		}
+	}
+
+	return sendWARequest(cfg, recipient, whatsapp.TextMessage(message), *dryRun)
 }
 
 // ─── send-buttons ───
@@ -136,31 +172,20 @@ func runWASendButtons(args []string) error {
 	}
 
 	buttonEntries := splitAndCleanCSV(*buttonsFlag)
-	if len(buttonEntries) == 0 || len(buttonEntries) > 3 {
-		return fmt.Errorf("--buttons يجب أن يحتوي من 1 إلى 3 أزرار")
+	if len(buttonEntries) == 0 || len(buttonEntries) > whatsapp.MaxButtons {
+		return usageErrorf("--buttons يجب أن يحتوي من 1 إلى 3 أزرار")
 	}
 
-	buttons := make([]map[string]any, 0, len(buttonEntries))
+	buttons := make([]whatsapp.Button, 0, len(buttonEntries))
 	for _, entry := range buttonEntries {
 		pair := strings.SplitN(entry, ":", 2)
 		if len(pair) != 2 || strings.TrimSpace(pair[0]) == "" || strings.TrimSpace(pair[1]) == "" {
-			return fmt.Errorf("زر غير صحيح %q، الصيغة المطلوبة: id:title", entry)
+			return usageErrorf("زر غير صحيح %q، الصيغة المطلوبة: id:title", entry)
 		}
-		buttons = append(buttons, map[string]any{
-			"type":  "reply",
-			"reply": map[string]string{"id": strings.TrimSpace(pair[0]), "title": strings.TrimSpace(pair[1])},
-		})
+		buttons = append(buttons, whatsapp.Button{ID: strings.TrimSpace(pair[0]), Title: strings.TrimSpace(pair[1])})
 	}
 
-	data := map[string]any{
-		"type": "interactive",
-		"interactive": map[string]any{
-			"type":   "button",
-			"body":   map[string]string{"text": trimFlag(bodyFlag)},
-			"action": map[string]any{"buttons": buttons},
-		},
-	}
-	return sendWARequest(cfg, recipient, data, *dryRun)
+	return sendWARequest(cfg, recipient, whatsapp.ButtonsMessage(trimFlag(bodyFlag), buttons), *dryRun)
 }
 
 // ─── send-list ───
@@ -192,39 +217,31 @@ func runWASendList(args []string) error {
 	}
 
 	rowEntries := splitAndCleanCSV(*rowsFlag)
-	if len(rowEntries) == 0 || len(rowEntries) > 10 {
-		return fmt.Errorf("--rows يجب أن يحتوي من 1 إلى 10 عناصر")
+	if len(rowEntries) == 0 || len(rowEntries) > whatsapp.MaxListRows {
+		return usageErrorf("--rows يجب أن يحتوي من 1 إلى 10 عناصر")
 	}
 
-	rows := make([]map[string]string, 0, len(rowEntries))
+	rows := make([]whatsapp.Row, 0, len(rowEntries))
 	for _, entry := range rowEntries {
 		parts := strings.SplitN(entry, ":", 3)
 		if len(parts) != 3 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" || strings.TrimSpace(parts[2]) == "" {
-			return fmt.Errorf("عنصر غير صحيح %q، الصيغة المطلوبة: id:title:description", entry)
+			return usageErrorf("عنصر غير صحيح %q، الصيغة المطلوبة: id:title:description", entry)
 		}
-		rows = append(rows, map[string]string{
-			"id":          strings.TrimSpace(parts[0]),
-			"title":       strings.TrimSpace(parts[1]),
-			"description": strings.TrimSpace(parts[2]),
+		rows = append(rows, whatsapp.Row{
+			ID:          strings.TrimSpace(parts[0]),
+			Title:       strings.TrimSpace(parts[1]),
+			Description: strings.TrimSpace(parts[2]),
 		})
 	}
 
-	data := map[string]any{
-		"type": "interactive",
-		"interactive": map[string]any{
-			"type":   "list",
-			"header": map[string]string{"type": "text", "text": trimFlag(headerFlag)},
-			"body":   map[string]string{"text": trimFlag(bodyFlag)},
-			"footer": map[string]string{"text": trimFlag(footerFlag)},
-			"action": map[string]any{
-				"button": trimFlag(buttonFlag),
-				"sections": []map[string]any{
-					{"title": trimFlag(sectionTitleFlag), "rows": rows},
-				},
-			},
-		},
-	}
-	return sendWARequest(cfg, recipient, data, *dryRun)
+	msg := whatsapp.ListMessage(whatsapp.List{
+		Header:   trimFlag(headerFlag),
+		Body:     trimFlag(bodyFlag),
+		Footer:   trimFlag(footerFlag),
+		Button:   trimFlag(buttonFlag),
+		Sections: []whatsapp.Section{{Title: trimFlag(sectionTitleFlag), Rows: rows}},
+	})
+	return sendWARequest(cfg, recipient, msg, *dryRun)
 }
 
 // ─── send-image ───
@@ -243,13 +260,7 @@ func runWASendImage(args []string) error {
 		return err
 	}
 
-	img := map[string]string{"link": trimFlag(linkFlag)}
-	if c := trimFlag(captionFlag); c != "" {
-		img["caption"] = c
-	}
-
-	data := map[string]any{"type": "image", "image": img}
-	return sendWARequest(cfg, recipient, data, *dryRun)
+	return sendWARequest(cfg, recipient, whatsapp.ImageMessage(trimFlag(linkFlag), trimFlag(captionFlag)), *dryRun)
 }
 
 // ─── send-video ───
@@ -268,13 +279,7 @@ func runWASendVideo(args []string) error {
 		return err
 	}
 
-	vid := map[string]string{"link": trimFlag(linkFlag)}
-	if c := trimFlag(captionFlag); c != "" {
-		vid["caption"] = c
-	}
-
-	data := map[string]any{"type": "video", "video": vid}
-	return sendWARequest(cfg, recipient, data, *dryRun)
+	return sendWARequest(cfg, recipient, whatsapp.VideoMessage(trimFlag(linkFlag), trimFlag(captionFlag)), *dryRun)
 }
 
 // ─── send-audio ───
@@ -292,11 +297,7 @@ func runWASendAudio(args []string) error {
 		return err
 	}
 
-	data := map[string]any{
-		"type":  "audio",
-		"audio": map[string]string{"link": trimFlag(linkFlag)},
-	}
-	return sendWARequest(cfg, recipient, data, *dryRun)
+	return sendWARequest(cfg, recipient, whatsapp.AudioMessage(trimFlag(linkFlag)), *dryRun)
 }
 
 // ─── send-document ───
@@ -316,16 +317,8 @@ func runWASendDocument(args []string) error {
 		return err
 	}
 
-	doc := map[string]string{"link": trimFlag(linkFlag)}
-	if c := trimFlag(captionFlag); c != "" {
-		doc["caption"] = c
-	}
-	if f := trimFlag(filenameFlag); f != "" {
-		doc["filename"] = f
-	}
-
-	data := map[string]any{"type": "document", "document": doc}
-	return sendWARequest(cfg, recipient, data, *dryRun)
+	msg := whatsapp.DocumentMessage(trimFlag(linkFlag), trimFlag(captionFlag), trimFlag(filenameFlag))
+	return sendWARequest(cfg, recipient, msg, *dryRun)
 }
 
 // ─── send-location ───
@@ -351,21 +344,21 @@ func runWASendLocation(args []string) error {
 
 	lat, err := strconv.ParseFloat(trimFlag(latFlag), 64)
 	if err != nil {
-		return fmt.Errorf("قيمة --lat غير صحيحة: %v", err)
+		return usageErrorf("قيمة --lat غير صحيحة: %v", err)
 	}
 	lng, err := strconv.ParseFloat(trimFlag(lngFlag), 64)
 	if err != nil {
-		return fmt.Errorf("قيمة --lng غير صحيحة: %v", err)
+		return usageErrorf("قيمة --lng غير صحيحة: %v", err)
 	}
 
-	params := map[string]any{
-		"phone":   recipient,
-		"lat":     lat,
-		"lng":     lng,
-		"address": trimFlag(addressFlag),
-		"name":    trimFlag(nameFlag),
+	loc := whatsapp.Location{
+		Phone:   recipient,
+		Lat:     lat,
+		Lng:     lng,
+		Address: trimFlag(addressFlag),
+		Name:    trimFlag(nameFlag),
 	}
-	return sendWACustomPath(cfg, "message/location", params, *dryRun)
+	return sendWACustomPath(cfg, whatsapp.PathLocation, loc, *dryRun)
 }
 
 // ─── send-contact ───
@@ -386,122 +379,229 @@ func runWASendContact(args []string) error {
 	if err := requireNonEmpty(trimFlag(phoneFlag), "--phone"); err != nil {
 		return err
 	}
+	country, err := resolveCountry(fs.Lookup("country").Value.String())
+	if err != nil {
+		return err
+	}
+	contactPhone, err := phone.Normalize(trimFlag(phoneFlag), country)
+	if err != nil {
+		return &validationError{msg: "--phone: " + err.Error()}
+	}
 
-	contactName := trimFlag(nameFlag)
-	nameParts := strings.SplitN(contactName, " ", 2)
-	firstName := nameParts[0]
-	lastName := ""
-	if len(nameParts) > 1 {
-		lastName = nameParts[1]
-	}
-
-	params := map[string]any{
-		"phone": recipient,
-		"contacts": []map[string]any{
-			{
-				"name": map[string]string{
-					"formatted_name": contactName,
-					"first_name":     firstName,
-					"last_name":      lastName,
-				},
-				"phones": []map[string]any{
-					{"phone": trimFlag(phoneFlag), "type": "CELL"},
-				},
-			},
-		},
-	}
-	return sendWACustomPath(cfg, "message/contact", params, *dryRun)
+	// The card keeps the plus so the number stays dialable from any country.
+	card := whatsapp.NewContactCard(trimFlag(nameFlag), "+"+contactPhone)
+	req := whatsapp.NewContactRequest(recipient, card)
+	return sendWACustomPath(cfg, req.Path, req.Params, *dryRun)
 }
 
-// ─── shared WA request senders ───
+// ─── send-template ───
 
-func sendWARequest(cfg waConfig, to string, data map[string]any, dryRun bool) error {
-	data["messaging_product"] = "whatsapp"
-	data["to"] = to
+// stringList collects a repeated flag in the order given.
+type stringList []string
 
-	payload := map[string]any{
-		"path": "global",
-		"params": map[string]any{
-			"url":    "messages",
-			"method": "post",
-			"data":   data,
-		},
-	}
+func (l *stringList) String() string { return strings.Join(*l, ",") }
 
-	endpoint := cfg.BaseURL + "/" + cfg.ProjectID
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
 
-	if dryRun {
-		return dryRunPrint(http.MethodPost, endpoint, payload)
-	}
+func runWASendTemplate(args []string) error {
+	fs := flag.NewFlagSet("wa send-template", flag.ContinueOnError)
+	appKey, apiSecret, projectID, to, baseURL, dryRun := waBaseFlags(fs)
+	nameFlag := fs.String("name", "", "اسم القالب المعتمد في واتساب")
+	languageFlag := fs.String("language", "", "رمز لغة القالب مثل ar أو en_US")
+	var params, buttonPayloads, buttonURLs stringList
+	fs.Var(&params, "param", "قيمة متغير في نص القالب {{1}} {{2}}... بالترتيب (يتكرر)")
+	headerTextFlag := fs.String("header-text", "", "قيمة متغير الترويسة النصية")
+	headerImageFlag := fs.String("header-image", "", "رابط صورة الترويسة")
+	headerVideoFlag := fs.String("header-video", "", "رابط فيديو الترويسة")
+	headerDocumentFlag := fs.String("header-document", "", "رابط مستند الترويسة")
+	headerFilenameFlag := fs.String("header-filename", "", "اسم ملف مستند الترويسة (اختياري)")
+	fs.Var(&buttonPayloads, "button-payload", "حمولة زر رد سريع بصيغة index:payload (يتكرر)")
+	fs.Var(&buttonURLs, "button-url", "لاحقة رابط زر بصيغة index:suffix (يتكرر)")
+	componentsFlag := fs.String("components", "", "مكونات القالب JSON كما في واتساب، أو @ملف")
 
-	body, err := json.Marshal(payload)
+	cfg, recipient, err := parseWAFlags(fs, args, appKey, apiSecret, projectID, to, baseURL)
 	if err != nil {
 		return err
 	}
-
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
-	if err != nil {
+	if err := requireNonEmpty(trimFlag(nameFlag), "--name"); err != nil {
+		return err
+	}
+	if err := requireNonEmpty(trimFlag(languageFlag), "--language"); err != nil {
 		return err
 	}
-	req.Header.Set("Authorization", basicAuthHeader(cfg.AppKey, cfg.APISecret))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
 
-	resBody, status, err := doRequest(req)
+	tmpl := whatsapp.Template{
+		Name:     trimFlag(nameFlag),
+		Language: whatsapp.TemplateLanguage{Code: trimFlag(languageFlag)},
+	}
+	if raw := trimFlag(componentsFlag); raw != "" {
+		structured := len(params) + len(buttonPayloads) + len(buttonURLs)
+		for _, f := range []*string{headerTextFlag, headerImageFlag, headerVideoFlag, headerDocumentFlag, headerFilenameFlag} {
+			if trimFlag(f) != "" {
+				structured++
+			}
+		}
+		if structured > 0 {
+			return usageErrorf("--components لا يجتمع مع --param و --header-* و --button-*")
+		}
+		tmpl.RawComponents, err = parseTemplateComponents(raw)
+	} else {
+		tmpl.Components, err = buildTemplateComponents(params,
+			trimFlag(headerTextFlag), trimFlag(headerImageFlag), trimFlag(headerVideoFlag),
+			trimFlag(headerDocumentFlag), trimFlag(headerFilenameFlag),
+			buttonPayloads, buttonURLs)
+	}
 	if err != nil {
 		return err
 	}
-	return printResponse(resBody, status)
+
+	return sendWARequest(cfg, recipient, whatsapp.TemplateMessage(tmpl), *dryRun)
 }
 
-func sendWACustomPath(cfg waConfig, path string, params map[string]any, dryRun bool) error {
-	payload := map[string]any{
-		"path":   path,
-		"params": params,
+// parseTemplateComponents reads --components, given inline or as @file. It
+// only checks that the value is a JSON array of objects; the array itself is
+// sent as written.
+func parseTemplateComponents(raw string) (json.RawMessage, error) {
+	data := []byte(raw)
+	if path, ok := strings.CutPrefix(raw, "@"); ok {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, validationErrorf("تعذر قراءة --components: %v", err)
+		}
+	}
+	var components []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &components); err != nil {
+		return nil, validationErrorf("--components ليس مصفوفة JSON من الكائنات: %v", err)
 	}
+	return json.RawMessage(bytes.TrimSpace(data)), nil
+}
 
-	endpoint := cfg.BaseURL + "/" + cfg.ProjectID
+// buildTemplateComponents turns the header, --param and --button-* flags into
+// components in the order WhatsApp lists them: header, body, buttons by index.
+func buildTemplateComponents(params []string, headerText, headerImage, headerVideo, headerDocument, headerFilename string, payloads, urls []string) ([]whatsapp.TemplateComponent, error) {
+	var components []whatsapp.TemplateComponent
 
-	if dryRun {
-		return dryRunPrint(http.MethodPost, endpoint, payload)
+	headers := 0
+	for _, h := range []string{headerText, headerImage, headerVideo, headerDocument} {
+		if h != "" {
+			headers++
+		}
+	}
+	if headers > 1 {
+		return nil, usageErrorf("استخدم واحدًا فقط من --header-text و --header-image و --header-video و --header-document")
+	}
+	if headerFilename != "" && headerDocument == "" {
+		return nil, usageErrorf("--header-filename يحتاج --header-document")
+	}
+	switch {
+	case headerText != "":
+		components = append(components, whatsapp.HeaderTextComponent(headerText))
+	case headerImage != "":
+		components = append(components, whatsapp.HeaderMediaComponent("image", headerImage, ""))
+	case headerVideo != "":
+		components = append(components, whatsapp.HeaderMediaComponent("video", headerVideo, ""))
+	case headerDocument != "":
+		components = append(components, whatsapp.HeaderMediaComponent("document", headerDocument, headerFilename))
 	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return err
+	if len(params) > 0 {
+		for i, p := range params {
+			if strings.TrimSpace(p) == "" {
+				return nil, validationErrorf("--param رقم %d فارغ", i+1)
+			}
+		}
+		components = append(components, whatsapp.BodyComponent(params...))
 	}
 
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
-	if err != nil {
-		return err
+	buttons := map[int]whatsapp.TemplateComponent{}
+	for _, b := range []struct {
+		flag    string
+		entries []string
+		build   func(int, string) whatsapp.TemplateComponent
+	}{
+		{"--button-payload", payloads, whatsapp.QuickReplyButtonComponent},
+		{"--button-url", urls, whatsapp.URLButtonComponent},
+	} {
+		for _, entry := range b.entries {
+			indexStr, value, ok := strings.Cut(entry, ":")
+			index, err := strconv.Atoi(strings.TrimSpace(indexStr))
+			if !ok || err != nil || strings.TrimSpace(value) == "" {
+				return nil, usageErrorf("%s غير صحيح %q، الصيغة المطلوبة: index:value", b.flag, entry)
+			}
+			if index < 0 || index >= whatsapp.MaxTemplateButtons {
+				return nil, usageErrorf("%s: رقم الزر %d خارج النطاق 0-%d", b.flag, index, whatsapp.MaxTemplateButtons-1)
+			}
+			if _, dup := buttons[index]; dup {
+				return nil, usageErrorf("الزر %d محدد أكثر من مرة", index)
+			}
+			buttons[index] = b.build(index, strings.TrimSpace(value))
+		}
+	}
+	for i := 0; i < whatsapp.MaxTemplateButtons; i++ {
+		if c, ok := buttons[i]; ok {
+			components = append(components, c)
+		}
+	}
+	return components, nil
+}
+
+// ─── shared WA request senders ───
+
+func sendWARequest(cfg waConfig, to string, msg whatsapp.Message, dryRun bool) error {
+	return sendWA(cfg, whatsapp.NewMessageRequest(to, msg), dryRun)
+}
+
+func sendWACustomPath(cfg waConfig, path string, params any, dryRun bool) error {
+	return sendWA(cfg, whatsapp.Request{Path: path, Params: params}, dryRun)
+}
+
+func sendWA(cfg waConfig, req whatsapp.Request, dryRun bool) error {
+	client := cfg.client()
+
+	if dryRun {
+		return dryRunPrint(http.MethodPost, client.Endpoint(), req)
 	}
-	req.Header.Set("Authorization", basicAuthHeader(cfg.AppKey, cfg.APISecret))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
 
-	resBody, status, err := doRequest(req)
+	res, err := client.Do(context.Background(), req)
 	if err != nil {
-		return err
+		return reportAPIError(err)
+	}
+	t := &table{header: []string{"message_id"}}
+	for _, m := range res.Messages {
+		t.rows = append(t.rows, []string{m.ID})
 	}
-	return printResponse(resBody, status)
+	return emitResult(res.Meta, t)
 }
 
-func printWAUsage() {
-	fmt.Println("أوامر WhatsApp:")
-	fmt.Println("")
-	fmt.Println("  4jawaly-cli wa send-text      --to <رقم> --message <نص>")
-	fmt.Println("  4jawaly-cli wa send-buttons   --to <رقم> --body <نص> --buttons <id:title,...>")
-	fmt.Println("  4jawaly-cli wa send-list      --to <رقم> --header <..> --body <..> --button <..> --section-title <..> --rows <id:t:d,...>")
-	fmt.Println("  4jawaly-cli wa send-image     --to <رقم> --link <رابط> [--caption <وصف>]")
-	fmt.Println("  4jawaly-cli wa send-video     --to <رقم> --link <رابط> [--caption <وصف>]")
-	fmt.Println("  4jawaly-cli wa send-audio     --to <رقم> --link <رابط>")
-	fmt.Println("  4jawaly-cli wa send-document  --to <رقم> --link <رابط> [--caption <وصف>] [--filename <اسم>]")
-	fmt.Println("  4jawaly-cli wa send-location  --to <رقم> --lat <عرض> --lng <طول> [--address <..>] [--name <..>]")
-	fmt.Println("  4jawaly-cli wa send-contact   --to <رقم> --name <الاسم> --phone <رقم جهة الاتصال>")
-	fmt.Println("")
-	fmt.Println("خيارات مشتركة:")
-	fmt.Println("  --app-key       مفتاح API (أو FOURJAWALY_APP_KEY)")
-	fmt.Println("  --api-secret    سر API (أو FOURJAWALY_API_SECRET)")
-	fmt.Println("  --project-id    رقم المشروع (أو FOURJAWALY_WHATSAPP_PROJECT_ID)")
-	fmt.Println("  --dry-run       معاينة بدون إرسال فعلي")
+func printWAUsage(w io.Writer) {
+	fmt.Fprintln(w, "أوامر WhatsApp:")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "  4jawaly-cli wa send-text      --to <رقم> --message <نص> | --template <اسم> [--var key=value]...")
+	fmt.Fprintln(w, "  4jawaly-cli wa send-buttons   --to <رقم> --body <نص> --buttons <id:title,...>")
+	fmt.Fprintln(w, "  4jawaly-cli wa send-list      --to <رقم> --header <..> --body <..> --button <..> --section-title <..> --rows <id:t:d,...>")
+	fmt.Fprintln(w, "  4jawaly-cli wa send-image     --to <رقم> --link <رابط> [--caption <وصف>]")
+	fmt.Fprintln(w, "  4jawaly-cli wa send-video     --to <رقم> --link <رابط> [--caption <وصف>]")
+	fmt.Fprintln(w, "  4jawaly-cli wa send-audio     --to <رقم> --link <رابط>")
+	fmt.Fprintln(w, "  4jawaly-cli wa send-document  --to <رقم> --link <رابط> [--caption <وصف>] [--filename <اسم>]")
+	fmt.Fprintln(w, "  4jawaly-cli wa send-location  --to <رقم> --lat <عرض> --lng <طول> [--address <..>] [--name <..>]")
+	fmt.Fprintln(w, "  4jawaly-cli wa send-contact   --to <رقم> --name <الاسم> --phone <رقم جهة الاتصال>")
+	fmt.Fprintln(w, "  4jawaly-cli wa send-template  --to <رقم> --name <قالب> --language <رمز> [--param <قيمة>]...")
+	fmt.Fprintln(w, "                                [--header-text|--header-image|--header-video|--header-document <..>]")
+	fmt.Fprintln(w, "                                [--button-payload <index:payload>]... [--button-url <index:suffix>]...")
+	fmt.Fprintln(w, "                                | --components <JSON أو @ملف>")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "خيارات مشتركة:")
+	fmt.Fprintln(w, "  --app-key       مفتاح API (أو FOURJAWALY_APP_KEY)")
+	fmt.Fprintln(w, "  --api-secret    سر API (أو FOURJAWALY_API_SECRET)؛ - للقراءة من stdin")
+	fmt.Fprintln(w, "  --api-secret-file  ملف يحتوي السر (أو FOURJAWALY_API_SECRET_FILE)")
+	fmt.Fprintln(w, "  --project-id    رقم المشروع (أو FOURJAWALY_WHATSAPP_PROJECT_ID)")
+	fmt.Fprintln(w, "  --country       الدولة للأرقام المحلية مثل 05XXXXXXXX (الافتراضي SA)")
+	fmt.Fprintln(w, "  --dry-run       معاينة بدون إرسال فعلي")
+	fmt.Fprintln(w, "  --output        صيغة المخرجات: text|json|table|csv|quiet")
+	fmt.Fprintln(w, "  --profile       ملف التعريف من ملف الإعدادات (أو FOURJAWALY_PROFILE)")
+	fmt.Fprintln(w, "  --retries       إعادة المحاولة عند أخطاء الشبكة و 429 و 5xx (الافتراضي 2)")
+	fmt.Fprintln(w, "  --retry-sends   إعادة الإرسال أيضًا بعد 5xx أو انقطاع قد يكون بعد وصول الطلب (قد يكرر الرسالة)")
 }
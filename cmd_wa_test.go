@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"fourjawaly-cli/fourjawaly/whatsapp"
+)
+
+// templateComponentsOf marshals req as it would be sent and returns the
+// template components it carries, decoded generically.
+func templateComponentsOf(t *testing.T, req whatsapp.Request) any {
+	t.Helper()
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent struct {
+		Params struct {
+			Data struct {
+				Template struct {
+					Components any `json:"components"`
+				} `json:"template"`
+			} `json:"data"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(data, &sent); err != nil {
+		t.Fatal(err)
+	}
+	return sent.Params.Data.Template.Components
+}
+
+func TestTemplateComponentsRoundTrip(t *testing.T) {
+	// Parameter types and fields the typed components do not model must
+	// reach the API unchanged, numeric button index included.
+	const components = `[
+		{"type": "header", "parameters": [{"type": "location", "location": {"latitude": "24.7", "longitude": "46.6", "name": "HQ"}}]},
+		{"type": "body", "parameters": [
+			{"type": "text", "text": "أحمد"},
+			{"type": "currency", "currency": {"fallback_value": "SAR 10.50", "code": "SAR", "amount_1000": 10500}},
+			{"type": "date_time", "date_time": {"fallback_value": "20 Oct 2026"}}
+		]},
+		{"type": "button", "sub_type": "quick_reply", "index": 0, "parameters": [{"type": "payload", "payload": "yes"}]},
+		{"type": "button", "sub_type": "copy_code", "index": "1", "parameters": [{"type": "coupon_code", "coupon_code": "SAVE10"}]}
+	]`
+	var want any
+	if err := json.Unmarshal([]byte(components), &want); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "components.json")
+	if err := os.WriteFile(file, []byte(components+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, arg := range []string{components, "@" + file} {
+		raw, err := parseTemplateComponents(arg)
+		if err != nil {
+			t.Fatalf("parseTemplateComponents(%.20q): %v", arg, err)
+		}
+		msg := whatsapp.TemplateMessage(whatsapp.Template{
+			Name:          "order_update",
+			Language:      whatsapp.TemplateLanguage{Code: "ar"},
+			RawComponents: raw,
+		})
+		if got := templateComponentsOf(t, whatsapp.NewMessageRequest("966500000000", msg)); !reflect.DeepEqual(got, want) {
+			t.Errorf("components sent as\n%v\nwant\n%v", got, want)
+		}
+	}
+}
+
+func TestParseTemplateComponentsRejects(t *testing.T) {
+	for _, arg := range []string{
+		`{"type": "body"}`,
+		`[1, 2]`,
+		`[{"type": "body"`,
+		`@/nonexistent/components.json`,
+	} {
+		if _, err := parseTemplateComponents(arg); err == nil {
+			t.Errorf("parseTemplateComponents(%q) succeeded, want an error", arg)
+		}
+	}
+}
+
+func TestBuildTemplateComponents(t *testing.T) {
+	got, err := buildTemplateComponents([]string{"أحمد", "#1042"}, "", "https://example.com/a.jpg", "", "", "",
+		[]string{"0:confirm"}, []string{"1:orders/1042"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := whatsapp.TemplateMessage(whatsapp.Template{Name: "t", Language: whatsapp.TemplateLanguage{Code: "ar"}, Components: got})
+
+	var want any
+	json.Unmarshal([]byte(`[
+		{"type": "header", "parameters": [{"type": "image", "image": {"link": "https://example.com/a.jpg"}}]},
+		{"type": "body", "parameters": [{"type": "text", "text": "أحمد"}, {"type": "text", "text": "#1042"}]},
+		{"type": "button", "sub_type": "quick_reply", "index": "0", "parameters": [{"type": "payload", "payload": "confirm"}]},
+		{"type": "button", "sub_type": "url", "index": "1", "parameters": [{"type": "text", "text": "orders/1042"}]}
+	]`), &want)
+	if sent := templateComponentsOf(t, whatsapp.NewMessageRequest("966500000000", msg)); !reflect.DeepEqual(sent, want) {
+		t.Errorf("components sent as\n%v\nwant\n%v", sent, want)
+	}
+}
+
+func TestWASendTemplateComponentsConflict(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(config, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FOURJAWALY_CONFIG", config)
+	t.Setenv("FOURJAWALY_APP_KEY", "k")
+	t.Setenv("FOURJAWALY_API_SECRET", "s")
+
+	base := []string{"--project-id", "1", "--to", "0501234567", "--name", "order", "--language", "ar", "--components", `[{"type": "body"}]`, "--dry-run"}
+	for _, extra := range [][]string{
+		{"--param", "x"},
+		{"--header-text", "x"},
+		{"--header-document", "https://example.com/a.pdf"},
+		{"--header-filename", "invoice.pdf"},
+		{"--button-url", "0:x"},
+	} {
+		var usage *usageError
+		if err := runWASendTemplate(append(append([]string{}, base...), extra...)); !errors.As(err, &usage) {
+			t.Errorf("--components with %v = %v, want a usage error", extra, err)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// systemConfigPath is shared by every user of the machine; the per-user file
+// overrides it field by field.
+const systemConfigPath = "/etc/4jawaly-cli/config.yaml"
+
+// defaultProfileName is used when neither --profile, FOURJAWALY_PROFILE nor
+// default_profile names one.
+const defaultProfileName = "default"
+
+// profileName is set by --profile (before or after the command name); empty
+// falls back to FOURJAWALY_PROFILE, then default_profile in the config file.
+var profileName string
+
+// profile is one named set of credentials and defaults from the config file.
+// Flags and environment variables always take precedence over it.
+type profile struct {
+	Name       string
+	AppKey     string
+	APISecret  string
+	ProjectID  string
+	Sender     string
+	SMSBaseURL string
+	WABaseURL  string
+}
+
+// profileKeys maps the YAML keys of a profile to its fields.
+var profileKeys = map[string]func(p *profile) *string{
+	"app_key":      func(p *profile) *string { return &p.AppKey },
+	"api_secret":   func(p *profile) *string { return &p.APISecret },
+	"project_id":   func(p *profile) *string { return &p.ProjectID },
+	"sender":       func(p *profile) *string { return &p.Sender },
+	"sms_base_url": func(p *profile) *string { return &p.SMSBaseURL },
+	"wa_base_url":  func(p *profile) *string { return &p.WABaseURL },
+}
+
+type configFile struct {
+	DefaultProfile string
+	Profiles       map[string]*profile
+}
+
+// configPaths lists the config files to read, lowest precedence first.
+// FOURJAWALY_CONFIG replaces both.
+func configPaths() []string {
+	if path := envOrDefault("FOURJAWALY_CONFIG", ""); path != "" {
+		return []string{path}
+	}
+	paths := []string{systemConfigPath}
+	if dir, err := userConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "config.yaml"))
+	}
+	return paths
+}
+
+// userConfigDir is the per-user directory of the config files:
+// $XDG_CONFIG_HOME/4jawaly (or ~/.config/4jawaly) on every OS, as documented.
+func userConfigDir() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "4jawaly"), nil
+}
+
+// loadConfig reads and merges the config files. Missing files are skipped,
+// except one named by FOURJAWALY_CONFIG.
+func loadConfig() (*configFile, error) {
+	merged := &configFile{Profiles: map[string]*profile{}}
+	explicit := envOrDefault("FOURJAWALY_CONFIG", "") != ""
+	for _, path := range configPaths() {
+		cfg, err := readConfigFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			if explicit {
+				return nil, validationErrorf("ملف الإعدادات %s غير موجود (FOURJAWALY_CONFIG)", path)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		merged.DefaultProfile = firstNonEmpty(cfg.DefaultProfile, merged.DefaultProfile)
+		for name, p := range cfg.Profiles {
+			base, ok := merged.Profiles[name]
+			if !ok {
+				base = &profile{Name: name}
+				merged.Profiles[name] = base
+			}
+			for _, field := range profileKeys {
+				if v := *field(p); v != "" {
+					*field(base) = v
+				}
+			}
+		}
+	}
+	return merged, nil
+}
+
+func readConfigFile(path string) (*configFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		return nil, validationErrorf("تعذر قراءة ملف الإعدادات: %v", err)
+	}
+	defer f.Close()
+
+	doc, err := parseYAML(f)
+	if err != nil {
+		return nil, validationErrorf("%s: %v", path, err)
+	}
+
+	cfg := &configFile{Profiles: map[string]*profile{}}
+	for key, value := range doc {
+		switch key {
+		case "default_profile":
+			s, ok := value.(string)
+			if !ok {
+				return nil, validationErrorf("%s: default_profile يجب أن يكون نصًا", path)
+			}
+			cfg.DefaultProfile = s
+		case "profiles":
+			if value == "" {
+				continue
+			}
+			profiles, ok := value.(map[string]any)
+			if !ok {
+				return nil, validationErrorf("%s: profiles يجب أن يحتوي ملفات تعريف بأسماء", path)
+			}
+			for name, fields := range profiles {
+				p, err := decodeProfile(name, fields)
+				if err != nil {
+					return nil, validationErrorf("%s: %v", path, err)
+				}
+				cfg.Profiles[name] = p
+			}
+		default:
+			return nil, validationErrorf("%s: مفتاح غير معروف %q", path, key)
+		}
+	}
+
+	if info, err := f.Stat(); err == nil && info.Mode().Perm()&0o077 != 0 {
+		for _, p := range cfg.Profiles {
+			if p.APISecret != "" {
+				progressf("تحذير: %s يحتوي api_secret ويمكن لغيرك قراءته، نفّذ: chmod 600 %s\n", path, path)
+				break
+			}
+		}
+	}
+	return cfg, nil
+}
+
+func decodeProfile(name string, value any) (*profile, error) {
+	p := &profile{Name: name}
+	if value == "" {
+		return p, nil
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("ملف التعريف %q يجب أن يحتوي مفاتيح مثل app_key", name)
+	}
+	for key, v := range fields {
+		field, ok := profileKeys[key]
+		if !ok {
+			return nil, fmt.Errorf("مفتاح غير معروف %q في ملف التعريف %q", key, name)
+		}
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s في ملف التعريف %q يجب أن يكون قيمة مفردة", key, name)
+		}
+		*field(p) = s
+	}
+	return p, nil
+}
+
+// selectedProfileName is the profile asked for, whether or not it exists.
+func (cfg *configFile) selectedProfileName() string {
+	return firstNonEmpty(profileName, envOrDefault("FOURJAWALY_PROFILE", ""), cfg.DefaultProfile, defaultProfileName)
+}
+
+var (
+	cachedProfile *profile
+	cachedErr     error
+)
+
+// activeProfile returns the selected profile, loading the config files on
+// first use. With no config and no profile asked for it returns an empty
+// "default" profile, so everything falls through to the built-in defaults.
+func activeProfile() (*profile, error) {
+	if cachedProfile == nil && cachedErr == nil {
+		cachedProfile, cachedErr = selectProfile()
+	}
+	return cachedProfile, cachedErr
+}
+
+func selectProfile() (*profile, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	asked := firstNonEmpty(profileName, envOrDefault("FOURJAWALY_PROFILE", ""))
+	name := cfg.selectedProfileName()
+	if p, ok := cfg.Profiles[name]; ok {
+		return p, nil
+	}
+	// A profile may live only in the credential store, from auth login.
+	if (asked == "" && cfg.DefaultProfile == "") || hasStoredCredentials(name) {
+		return &profile{Name: name}, nil
+	}
+
+	names := make([]string, 0, len(cfg.Profiles))
+	for n := range cfg.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil, validationErrorf("ملف التعريف %q غير موجود: لا توجد ملفات تعريف في %s", name, strings.Join(configPaths(), " أو "))
+	}
+	return nil, validationErrorf("ملف التعريف %q غير موجود (المتاح: %s)", name, strings.Join(names, ", "))
+}
+
+// parseYAML reads the subset of YAML the config file needs: nested mappings
+// by indentation, comments, and plain or quoted scalars. Lists and flow
+// styles are rejected rather than misread.
+func parseYAML(r io.Reader) (map[string]any, error) {
+	type level struct {
+		indent int
+		m      map[string]any
+	}
+	root := map[string]any{}
+	stack := []level{{indent: 0, m: root}}
+	// open is a key with no value on its line; the lines indented under it
+	// make it a mapping, and without any it is an empty string.
+	var open struct {
+		key    string
+		parent map[string]any
+		indent int
+	}
+
+	scanner := bufio.NewScanner(r)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		content := strings.TrimLeft(line, " ")
+		if content == "" || strings.HasPrefix(content, "#") || (n == 1 && content == "---") {
+			continue
+		}
+		if strings.HasPrefix(content, "\t") {
+			return nil, fmt.Errorf("السطر %d: استخدم المسافات بدل Tab للإزاحة", n)
+		}
+		if strings.HasPrefix(content, "- ") || content == "-" {
+			return nil, fmt.Errorf("السطر %d: القوائم غير مدعومة", n)
+		}
+		indent := len(line) - len(content)
+
+		if open.parent != nil {
+			if indent > open.indent {
+				child := map[string]any{}
+				open.parent[open.key] = child
+				stack = append(stack, level{indent: indent, m: child})
+			}
+			open.parent = nil
+		}
+		for len(stack) > 1 && indent < stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		top := stack[len(stack)-1]
+		if indent != top.indent {
+			return nil, fmt.Errorf("السطر %d: إزاحة غير متوقعة", n)
+		}
+
+		key, rest, ok := strings.Cut(content, ":")
+		if !ok || (rest != "" && rest[0] != ' ') {
+			return nil, fmt.Errorf("السطر %d: متوقع key: value", n)
+		}
+		key, err := yamlScalar(strings.TrimSpace(key))
+		if err != nil || key == "" {
+			return nil, fmt.Errorf("السطر %d: مفتاح غير صحيح", n)
+		}
+		if _, dup := top.m[key]; dup {
+			return nil, fmt.Errorf("السطر %d: المفتاح %q مكرر", n, key)
+		}
+		raw := stripComment(strings.TrimSpace(rest))
+		value, err := yamlScalar(raw)
+		if err != nil {
+			return nil, fmt.Errorf("السطر %d: %v", n, err)
+		}
+		top.m[key] = value
+		// Only a bare "key:" may open a mapping; key: "" is an empty string.
+		if raw == "" {
+			open.key, open.parent, open.indent = key, top.m, indent
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("السطر %d: %v", n+1, err)
+	}
+	return root, nil
+}
+
+// stripComment drops a trailing " # comment" outside quotes.
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' '):
+			return strings.TrimSpace(s[:i])
+		}
+	}
+	return s
+}
+
+func yamlScalar(s string) (string, error) {
+	switch {
+	case s == "" || s == "~" || s == "null":
+		return "", nil
+	case strings.HasPrefix(s, `"`):
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("نص بين علامتي تنصيص غير مكتمل: %s", s)
+		}
+		return v, nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("نص بين علامتي تنصيص غير مكتمل: %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case strings.HasPrefix(s, "[") || strings.HasPrefix(s, "{") || strings.HasPrefix(s, "|") || strings.HasPrefix(s, ">"):
+		return "", fmt.Errorf("الصيغة %q غير مدعومة، استخدم قيمة مفردة", s[:1])
+	}
+	return s, nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want map[string]any
+	}{
+		{
+			name: "comments",
+			in: `---
+# full-line comment
+default_profile: work # trailing comment
+  # indented comment
+sender: a#b
+url: "https://x/#frag" # comment after quotes
+`,
+			want: map[string]any{"default_profile": "work", "sender": "a#b", "url": "https://x/#frag"},
+		},
+		{
+			name: "quoted and unquoted values",
+			in: `plain: some value
+double: "with \"escapes\"\tand tab"
+single: 'it''s'
+colon: "a: b"
+number: 0501234567
+tilde: ~
+null_value: null
+empty_double: ""
+empty_single: ''
+`,
+			want: map[string]any{
+				"plain":        "some value",
+				"double":       "with \"escapes\"\tand tab",
+				"single":       "it's",
+				"colon":        "a: b",
+				"number":       "0501234567",
+				"tilde":        "",
+				"null_value":   "",
+				"empty_double": "",
+				"empty_single": "",
+			},
+		},
+		{
+			name: "nested profiles",
+			in: `default_profile: work
+profiles:
+  default:
+    app_key: k1
+  work:
+    app_key: k2
+    sender: ""
+    project_id: '12'
+  empty:
+other: x
+`,
+			want: map[string]any{
+				"default_profile": "work",
+				"profiles": map[string]any{
+					"default": map[string]any{"app_key": "k1"},
+					"work":    map[string]any{"app_key": "k2", "sender": "", "project_id": "12"},
+					"empty":   "",
+				},
+				"other": "x",
+			},
+		},
+		{
+			// A quoted empty value is a scalar: the profile after it must
+			// stay a sibling, not become its child.
+			name: "empty quoted value before a profile",
+			in: `profiles:
+  a:
+    sender: ""
+  b:
+    sender: ''
+    app_key: k
+`,
+			want: map[string]any{
+				"profiles": map[string]any{
+					"a": map[string]any{"sender": ""},
+					"b": map[string]any{"sender": "", "app_key": "k"},
+				},
+			},
+		},
+		{
+			name: "four-space indentation",
+			in:   "profiles:\n    work:\n        app_key: k\n",
+			want: map[string]any{"profiles": map[string]any{"work": map[string]any{"app_key": "k"}}},
+		},
+		{
+			name: "empty document",
+			in:   "# nothing yet\n\n",
+			want: map[string]any{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatalf("parseYAML: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseYAML =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		line string
+	}{
+		{name: "tab indentation", in: "profiles:\n\twork: x\n", line: "السطر 2:"},
+		{name: "indent under a scalar", in: "a: x\n  b: y\n", line: "السطر 2:"},
+		{name: "dedent to an unknown level", in: "profiles:\n    work:\n      app_key: k\n  other: x\n", line: "السطر 4:"},
+		{name: "deeper sibling", in: "profiles:\n  a: x\n    b: y\n", line: "السطر 3:"},
+		{name: "indent under a quoted empty value", in: "profiles:\n  a: \"\"\n    b: y\n", line: "السطر 3:"},
+		{name: "list", in: "profiles:\n  - work\n", line: "السطر 2:"},
+		{name: "missing colon", in: "a: x\njust text\n", line: "السطر 2:"},
+		{name: "no space after colon", in: "a:x\n", line: "السطر 1:"},
+		{name: "duplicate key", in: "a: x\nb: y\na: z\n", line: "السطر 3:"},
+		{name: "unterminated double quote", in: "a: \"x\n", line: "السطر 1:"},
+		{name: "unterminated single quote", in: "# c\na: 'x\n", line: "السطر 2:"},
+		{name: "flow mapping", in: "a: {b: c}\n", line: "السطر 1:"},
+		{name: "block scalar", in: "a: |\n", line: "السطر 1:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseYAML(strings.NewReader(tt.in))
+			if err == nil {
+				t.Fatal("parseYAML succeeded, want an error")
+			}
+			if !strings.HasPrefix(err.Error(), tt.line) {
+				t.Errorf("error %q does not start with %q", err, tt.line)
+			}
+		})
+	}
+}
+
+func TestReadConfigFileEmptyQuotedValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `default_profile: work
+profiles:
+  default:
+    sender: ""
+  work:
+    app_key: work-key
+    project_id: "7"
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := readConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Profiles) != 2 {
+		t.Fatalf("profiles = %v, want default and work", cfg.Profiles)
+	}
+	work := cfg.Profiles["work"]
+	if work == nil || work.AppKey != "work-key" || work.ProjectID != "7" {
+		t.Errorf("work = %+v", work)
+	}
+	if def := cfg.Profiles["default"]; def == nil || def.Sender != "" {
+		t.Errorf("default = %+v", def)
+	}
+}
+
+func TestConfigPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("FOURJAWALY_CONFIG", "")
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	want := []string{systemConfigPath, filepath.Join(home, ".config", "4jawaly", "config.yaml")}
+	if got := configPaths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("configPaths() = %v, want %v", got, want)
+	}
+
+	xdg := filepath.Join(home, "xdg")
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	want = []string{systemConfigPath, filepath.Join(xdg, "4jawaly", "config.yaml")}
+	if got := configPaths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("configPaths() with XDG_CONFIG_HOME = %v, want %v", got, want)
+	}
+
+	t.Setenv("FOURJAWALY_CONFIG", "/tmp/other.yaml")
+	if got := configPaths(); !reflect.DeepEqual(got, []string{"/tmp/other.yaml"}) {
+		t.Errorf("configPaths() with FOURJAWALY_CONFIG = %v", got)
+	}
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// credentials are what auth login stores for a profile.
+type credentials struct {
+	AppKey    string `json:"app_key"`
+	APISecret string `json:"api_secret"`
+}
+
+// credentialStore keeps credentials per profile name outside the config
+// file. Get returns nil, nil when the profile has nothing stored.
+type credentialStore interface {
+	Name() string
+	Get(profile string) (*credentials, error)
+	Has(profile string) bool
+	Set(profile string, c credentials) error
+	Delete(profile string) (bool, error)
+}
+
+// Store names for auth login --store.
+const (
+	storeKeyring = "keyring"
+	storeFile    = "file"
+)
+
+// credentialStores lists the available stores in lookup order: the desktop
+// keyring first, then the encrypted file.
+func credentialStores() []credentialStore {
+	var stores []credentialStore
+	if k := (keyringStore{}); k.available() {
+		stores = append(stores, k)
+	}
+	if f, err := newFileStore(); err == nil {
+		stores = append(stores, f)
+	}
+	return stores
+}
+
+var storedCache = map[string]*credentials{}
+
+// storedCredentials returns the credentials auth login saved for profile,
+// from the first store that has them, or nil.
+func storedCredentials(profile string) (*credentials, error) {
+	if c, ok := storedCache[profile]; ok {
+		return c, nil
+	}
+	var found *credentials
+	for _, s := range credentialStores() {
+		if !s.Has(profile) {
+			continue
+		}
+		c, err := s.Get(profile)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			found = c
+			break
+		}
+	}
+	storedCache[profile] = found
+	return found, nil
+}
+
+// hasStoredCredentials reports whether any store has profile, without
+// asking for a passphrase.
+func hasStoredCredentials(profile string) bool {
+	for _, s := range credentialStores() {
+		if s.Has(profile) {
+			return true
+		}
+	}
+	return false
+}
+
+// ─── keyring ───
+
+// keyringStore uses the freedesktop Secret Service (GNOME Keyring, KWallet)
+// through secret-tool, so no D-Bus client has to be linked in.
+type keyringStore struct{}
+
+const keyringService = "4jawaly-cli"
+
+func (keyringStore) Name() string { return storeKeyring }
+
+func (keyringStore) available() bool {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return false
+	}
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+func (keyringStore) lookup(profile string) ([]byte, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", keyringService, "profile", profile).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// secret-tool exits 1 with no output when nothing matches.
+		return nil, nil
+	}
+	return out, err
+}
+
+func (k keyringStore) Has(profile string) bool {
+	out, err := k.lookup(profile)
+	return err == nil && len(out) > 0
+}
+
+func (k keyringStore) Get(profile string) (*credentials, error) {
+	out, err := k.lookup(profile)
+	if err != nil || len(out) == 0 {
+		return nil, err
+	}
+	var c credentials
+	if err := json.Unmarshal(out, &c); err != nil {
+		return nil, fmt.Errorf("بيانات الدخول في keyring للملف %q تالفة: %v", profile, err)
+	}
+	return &c, nil
+}
+
+func (keyringStore) Set(profile string, c credentials) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("secret-tool", "store", "--label", "4jawaly-cli ("+profile+")", "service", keyringService, "profile", profile)
+	cmd.Stdin = bytes.NewReader(data)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool store: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (k keyringStore) Delete(profile string) (bool, error) {
+	if !k.Has(profile) {
+		return false, nil
+	}
+	if out, err := exec.Command("secret-tool", "clear", "service", keyringService, "profile", profile).CombinedOutput(); err != nil {
+		return false, fmt.Errorf("secret-tool clear: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return true, nil
+}
+
+// ─── encrypted file ───
+
+// pbkdf2Iterations is the work factor for new entries; each entry records
+// its own so it can be raised later.
+const pbkdf2Iterations = 600_000
+
+// fileStore keeps each profile's credentials encrypted with AES-256-GCM under
+// a key derived from a passphrase. Profile names stay readable so status and
+// logout work without the passphrase.
+type fileStore struct {
+	path string
+}
+
+type encryptedEntry struct {
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+type credentialsFile struct {
+	Version  int                        `json:"version"`
+	Profiles map[string]*encryptedEntry `json:"profiles"`
+}
+
+func newFileStore() (*fileStore, error) {
+	if path := envOrDefault("FOURJAWALY_CREDENTIALS_FILE", ""); path != "" {
+		return &fileStore{path: path}, nil
+	}
+	dir, err := userConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &fileStore{path: filepath.Join(dir, "credentials.json")}, nil
+}
+
+func (*fileStore) Name() string { return storeFile }
+
+func (s *fileStore) read() (*credentialsFile, error) {
+	cf := &credentialsFile{Version: 1, Profiles: map[string]*encryptedEntry{}}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cf, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cf); err != nil {
+		return nil, fmt.Errorf("%s تالف: %v", s.path, err)
+	}
+	if cf.Profiles == nil {
+		cf.Profiles = map[string]*encryptedEntry{}
+	}
+	return cf, nil
+}
+
+func (s *fileStore) write(cf *credentialsFile) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cf, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *fileStore) profiles() []string {
+	cf, err := s.read()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(cf.Profiles))
+	for name := range cf.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *fileStore) Has(profile string) bool {
+	cf, err := s.read()
+	return err == nil && cf.Profiles[profile] != nil
+}
+
+func (s *fileStore) Get(profile string) (*credentials, error) {
+	cf, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	entry := cf.Profiles[profile]
+	if entry == nil {
+		return nil, nil
+	}
+	passphrase, err := readPassphrase(fmt.Sprintf("كلمة المرور لبيانات الدخول (%s)", profile), false)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := entry.open(passphrase, profile)
+	if err != nil {
+		return nil, &authError{msg: fmt.Sprintf("تعذر فك تشفير بيانات الدخول للملف %q: كلمة المرور غير صحيحة أو الملف تالف", profile)}
+	}
+	var c credentials
+	if err := json.Unmarshal(plain, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *fileStore) Set(profile string, c credentials) error {
+	cf, err := s.read()
+	if err != nil {
+		return err
+	}
+	passphrase, err := readPassphrase(fmt.Sprintf("كلمة مرور جديدة لتشفير بيانات الدخول (%s)", profile), true)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	entry, err := sealEntry(passphrase, profile, plain)
+	if err != nil {
+		return err
+	}
+	cf.Profiles[profile] = entry
+	return s.write(cf)
+}
+
+func (s *fileStore) Delete(profile string) (bool, error) {
+	cf, err := s.read()
+	if err != nil || cf.Profiles[profile] == nil {
+		return false, err
+	}
+	delete(cf.Profiles, profile)
+	if len(cf.Profiles) == 0 {
+		return true, os.Remove(s.path)
+	}
+	return true, s.write(cf)
+}
+
+func sealEntry(passphrase []byte, profile string, plain []byte) (*encryptedEntry, error) {
+	e := &encryptedEntry{KDF: "pbkdf2-sha256", Iterations: pbkdf2Iterations, Salt: make([]byte, 16)}
+	if _, err := rand.Read(e.Salt); err != nil {
+		return nil, err
+	}
+	aead, err := e.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	e.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(e.Nonce); err != nil {
+		return nil, err
+	}
+	// The profile name is authenticated so entries cannot be swapped.
+	e.Data = aead.Seal(nil, e.Nonce, plain, []byte(profile))
+	return e, nil
+}
+
+func (e *encryptedEntry) open(passphrase []byte, profile string) ([]byte, error) {
+	if e.KDF != "pbkdf2-sha256" {
+		return nil, fmt.Errorf("kdf غير مدعوم %q", e.KDF)
+	}
+	aead, err := e.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, e.Nonce, e.Data, []byte(profile))
+}
+
+func (e *encryptedEntry) aead(passphrase []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, string(passphrase), e.Salt, e.Iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// readPassphrase takes the passphrase from FOURJAWALY_PASSPHRASE or asks on
+// the terminal; confirm asks twice, for a new passphrase.
+func readPassphrase(prompt string, confirmNew bool) ([]byte, error) {
+	if env := os.Getenv("FOURJAWALY_PASSPHRASE"); env != "" {
+		return []byte(env), nil
+	}
+	if !isTerminal(os.Stdin) {
+		return nil, &authError{msg: "بيانات الدخول مشفرة: شغّل الأمر في terminal أو عيّن FOURJAWALY_PASSPHRASE"}
+	}
+	first, err := promptLine(prompt, true)
+	if err != nil {
+		return nil, err
+	}
+	if first == "" {
+		return nil, validationErrorf("كلمة المرور فارغة")
+	}
+	if confirmNew {
+		again, err := promptLine("أعد كتابة كلمة المرور", true)
+		if err != nil {
+			return nil, err
+		}
+		if again != first {
+			return nil, validationErrorf("كلمتا المرور غير متطابقتين")
+		}
+	}
+	return []byte(first), nil
+}
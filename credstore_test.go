@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// legacyEntry was written by the original credentials file format, before
+// the key derivation moved to crypto/pbkdf2. It must keep opening.
+func legacyEntry(t *testing.T) *encryptedEntry {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString("sMXcnITusvKEUUXaSRQYlAdy+KZbvKiZdobhHPWYrZlWf4pov1ElDpEQ6BXm+06/hktVELHn6K/Q1ig=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &encryptedEntry{
+		KDF:        "pbkdf2-sha256",
+		Iterations: 1000,
+		Salt:       []byte("0123456789abcdef"),
+		Nonce:      []byte("nonce-12byte"),
+		Data:       data,
+	}
+}
+
+func TestEncryptedEntryOpensExistingFiles(t *testing.T) {
+	plain, err := legacyEntry(t).open([]byte("correct horse"), "work")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if want := `{"app_key":"key-1","api_secret":"secret-1"}`; string(plain) != want {
+		t.Errorf("open = %s, want %s", plain, want)
+	}
+}
+
+func TestEncryptedEntryRejects(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+		profile    string
+		edit       func(*encryptedEntry)
+	}{
+		{name: "wrong passphrase", passphrase: "wrong horse", profile: "work"},
+		// The profile name is authenticated: an entry moved to another
+		// profile must not open.
+		{name: "other profile", passphrase: "correct horse", profile: "default"},
+		{name: "tampered data", passphrase: "correct horse", profile: "work", edit: func(e *encryptedEntry) { e.Data[0] ^= 1 }},
+		{name: "other salt", passphrase: "correct horse", profile: "work", edit: func(e *encryptedEntry) { e.Salt[0] ^= 1 }},
+		{name: "other iterations", passphrase: "correct horse", profile: "work", edit: func(e *encryptedEntry) { e.Iterations++ }},
+		{name: "unsupported kdf", passphrase: "correct horse", profile: "work", edit: func(e *encryptedEntry) { e.KDF = "scrypt" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := legacyEntry(t)
+			if tt.edit != nil {
+				tt.edit(e)
+			}
+			if _, err := e.open([]byte(tt.passphrase), tt.profile); err == nil {
+				t.Error("open succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestSealEntryRoundTrip(t *testing.T) {
+	plain := []byte(`{"app_key":"k","api_secret":"s"}`)
+	e, err := sealEntry([]byte("passphrase"), "default", plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.KDF != "pbkdf2-sha256" || e.Iterations != pbkdf2Iterations || len(e.Salt) != 16 || len(e.Nonce) != 12 {
+		t.Errorf("entry = %s/%d salt %d nonce %d", e.KDF, e.Iterations, len(e.Salt), len(e.Nonce))
+	}
+	got, err := e.open([]byte("passphrase"), "default")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if string(got) != string(plain) {
+		t.Errorf("open = %s, want %s", got, plain)
+	}
+	if _, err := e.open([]byte("Passphrase"), "default"); err == nil {
+		t.Error("open with a wrong passphrase succeeded")
+	}
+
+	again, err := sealEntry([]byte("passphrase"), "default", plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again.Salt) == string(e.Salt) || string(again.Nonce) == string(e.Nonce) {
+		t.Error("salt and nonce are reused across entries")
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	t.Setenv("FOURJAWALY_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials.json"))
+	t.Setenv("FOURJAWALY_PASSPHRASE", "passphrase")
+	s, err := newFileStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := credentials{AppKey: "key", APISecret: "secret"}
+	if err := s.Set("work", want); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if !s.Has("work") || s.Has("default") {
+		t.Errorf("Has(work), Has(default) = %v, %v", s.Has("work"), s.Has("default"))
+	}
+	got, err := s.Get("work")
+	if err != nil || got == nil || *got != want {
+		t.Fatalf("Get = %+v, %v, want %+v", got, err, want)
+	}
+
+	t.Setenv("FOURJAWALY_PASSPHRASE", "wrong")
+	var auth *authError
+	if _, err := s.Get("work"); !errors.As(err, &auth) {
+		t.Errorf("Get with a wrong passphrase = %v, want an auth error", err)
+	}
+
+	if removed, err := s.Delete("work"); !removed || err != nil {
+		t.Errorf("Delete = %v, %v", removed, err)
+	}
+	if s.Has("work") {
+		t.Error("profile still stored after Delete")
+	}
+}
+
+func TestFileStorePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("FOURJAWALY_CREDENTIALS_FILE", "")
+	for _, tt := range []struct{ xdg, want string }{
+		{xdg: "", want: filepath.Join(home, ".config", "4jawaly", "credentials.json")},
+		{xdg: filepath.Join(home, "xdg"), want: filepath.Join(home, "xdg", "4jawaly", "credentials.json")},
+	} {
+		t.Setenv("XDG_CONFIG_HOME", tt.xdg)
+		s, err := newFileStore()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.path != tt.want {
+			t.Errorf("XDG_CONFIG_HOME=%q: path = %q, want %q", tt.xdg, s.path, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"fourjawaly-cli/fourjawaly"
+)
+
+// Exit codes, documented in RULES.md. Scripts branch on these, so existing
+// values must never change meaning.
+const (
+	exitOK          = 0
+	exitError       = 1 // unexpected error
+	exitUsage       = 2 // bad flags or arguments
+	exitAuth        = 3 // missing or rejected credentials
+	exitHTTP        = 4 // API answered with a non-2xx status
+	exitNetwork     = 5 // no response: timeout, DNS, connection reset
+	exitAPIRejected = 6 // API answered 2xx but refused the request (err_text)
+	exitPartial     = 7 // bulk send where some chunks failed
+	exitUnknown     = 8 // bulk send where some chunks got an inconclusive answer
+	exitBalance     = 9 // --require-balance and the balance does not cover the send
+
+	exitInterrupted = 130 // stopped by SIGINT/SIGTERM, as shells report it
+)
+
+var errInterrupted = errors.New("تم إيقاف الإرسال قبل اكتماله")
+
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// validationError is bad input data (numbers, files, templates) as opposed to
+// a malformed command line; it exits like a usage error but without the help.
+type validationError struct {
+	msg string
+}
+
+func (e *validationError) Error() string { return e.msg }
+
+func validationErrorf(format string, args ...any) error {
+	return &validationError{msg: fmt.Sprintf(format, args...)}
+}
+
+type authError struct {
+	msg string
+}
+
+func (e *authError) Error() string { return e.msg }
+
+type partialFailureError struct {
+	Success int
+	Failed  int
+	Total   int
+}
+
+func (e *partialFailureError) Error() string {
+	return fmt.Sprintf("فشل إرسال %d من أصل %d رقم", e.Failed, e.Total)
+}
+
+// unknownResultError is a bulk send with no failures but with chunks whose
+// response neither confirmed nor refused them; retrying them may double-send.
+type unknownResultError struct {
+	Unknown int
+	Total   int
+}
+
+func (e *unknownResultError) Error() string {
+	return fmt.Sprintf("نتيجة غير مؤكدة لـ %d من أصل %d رقم", e.Unknown, e.Total)
+}
+
+type insufficientBalanceError struct {
+	Balance  float64
+	Required float64
+}
+
+func (e *insufficientBalanceError) Error() string {
+	return fmt.Sprintf("الرصيد غير كافٍ: المتاح %s والمطلوب %s، لم يتم الإرسال", formatPoints(e.Balance), formatPoints(e.Required))
+}
+
+// parseFlags parses args into fs and marks any failure as a usage error.
+// flag.ErrHelp is passed through so that -h exits cleanly.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{msg: err.Error()}
+	}
+	return nil
+}
+
+// parseFlagsWithArgs is parseFlags for commands that take positional
+// arguments: flags may appear before or after them.
+func parseFlagsWithArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := parseFlags(fs, args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func exitCode(err error) int {
+	var (
+		usage   *usageError
+		invalid *validationError
+		auth    *authError
+		partial *partialFailureError
+		unknown *unknownResultError
+		balance *insufficientBalanceError
+		apiErr  *fourjawaly.APIError
+		netErr  *fourjawaly.NetworkError
+	)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usage), errors.As(err, &invalid):
+		return exitUsage
+	case errors.As(err, &auth):
+		return exitAuth
+	case errors.As(err, &partial):
+		return exitPartial
+	case errors.As(err, &unknown):
+		return exitUnknown
+	case errors.As(err, &balance):
+		return exitBalance
+	case errors.Is(err, errInterrupted):
+		return exitInterrupted
+	case errors.As(err, &apiErr):
+		switch {
+		case apiErr.Unauthorized():
+			return exitAuth
+		case apiErr.Rejected():
+			return exitAPIRejected
+		}
+		return exitHTTP
+	case errors.As(err, &netErr):
+		return exitNetwork
+	}
+	return exitError
+}
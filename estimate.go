@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"fourjawaly-cli/fourjawaly/sms"
+)
+
+// textEstimate is the cost of one distinct rendered text.
+type textEstimate struct {
+	sms.SegmentInfo
+	Numbers int    `json:"numbers"`
+	Total   int    `json:"total_segments"`
+	Preview string `json:"preview"`
+}
+
+// smsEstimate is the cost of a whole send, in segments.
+type smsEstimate struct {
+	Texts    []textEstimate `json:"texts"`
+	Numbers  int            `json:"numbers"`
+	Segments int            `json:"total_segments"`
+}
+
+func estimateMessages(messages []sms.Message) smsEstimate {
+	var est smsEstimate
+	for _, m := range messages {
+		info := sms.Segments(m.Text)
+		t := textEstimate{
+			SegmentInfo: info,
+			Numbers:     len(m.Numbers),
+			Total:       info.Segments * len(m.Numbers),
+			Preview:     previewText(m.Text, 40),
+		}
+		est.Texts = append(est.Texts, t)
+		est.Numbers += t.Numbers
+		est.Segments += t.Total
+	}
+	return est
+}
+
+// Summary is the one-line human form of the estimate.
+func (e smsEstimate) Summary() string {
+	if len(e.Texts) == 1 {
+		t := e.Texts[0]
+		return fmt.Sprintf("%s، %d حرف، %d جزء لكل رقم × %d رقم = %d جزء",
+			t.Encoding, t.Characters, t.Segments, e.Numbers, e.Segments)
+	}
+	return fmt.Sprintf("%d نص مختلف لـ %d رقم = %d جزء", len(e.Texts), e.Numbers, e.Segments)
+}
+
+func (e smsEstimate) table() *table {
+	t := &table{header: []string{"encoding", "characters", "units", "segments", "numbers", "total_segments", "preview"}}
+	for _, x := range e.Texts {
+		t.rows = append(t.rows, []string{
+			string(x.Encoding),
+			strconv.Itoa(x.Characters),
+			strconv.Itoa(x.Units),
+			strconv.Itoa(x.Segments),
+			strconv.Itoa(x.Numbers),
+			strconv.Itoa(x.Total),
+			x.Preview,
+		})
+	}
+	return t
+}
+
+func previewText(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "…"
+}
+
+func runSMSEstimate(args []string) error {
+	fs := flag.NewFlagSet("sms estimate", flag.ContinueOnError)
+	appKeyFlag := fs.String("app-key", "", "مفتاح API")
+	apiSecretFlag := fs.String("api-secret", "", "سر API (أو - للقراءة من stdin)")
+	addSecretFileFlag(fs)
+	messageFlag := fs.String("message", "", "نص الرسالة، ويقبل متغيرات مثل {{.name}} من أعمدة الملف")
+	rf := addRecipientFlags(fs)
+	count := fs.Int("recipients", 0, "عدد المستلمين بدل --to/--to-file")
+	checkBalanceFlag := fs.Bool("check-balance", false, "مقارنة التكلفة بالرصيد الحالي")
+	pointsPerSegment := fs.Float64("points-per-segment", 1, "النقاط المخصومة لكل جزء")
+	baseURLFlag := fs.String("base-url", "", "رابط API (الافتراضي: "+defaultSMSBaseURL+")")
+	addGlobalFlags(fs)
+	addRetryFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	message := trimFlag(messageFlag)
+	if err := requireNonEmpty(message, "--message"); err != nil {
+		return err
+	}
+	if *count < 0 {
+		return usageErrorf("--recipients لا يقبل قيمة سالبة")
+	}
+	if *pointsPerSegment <= 0 {
+		return usageErrorf("--points-per-segment يجب أن يكون أكبر من صفر")
+	}
+
+	var messages []sms.Message
+	duplicates := 0
+	if trimFlag(&rf.To)+trimFlag(&rf.ToFile) != "" {
+		if *count > 0 {
+			return usageErrorf("--recipients لا يجتمع مع --to أو --to-file")
+		}
+		recipients, dupes, err := rf.load()
+		if err != nil {
+			return err
+		}
+		duplicates = dupes
+		if messages, err = buildSMSMessages(message, "", recipients); err != nil {
+			return err
+		}
+	} else {
+		n := max(*count, 1)
+		messages = []sms.Message{{Text: message, Numbers: make([]string, n)}}
+	}
+
+	est := estimateMessages(messages)
+	progressf("%s\n", est.Summary())
+	result := map[string]any{
+		"texts":              est.Texts,
+		"numbers":            est.Numbers,
+		"total_segments":     est.Segments,
+		"duplicates_removed": duplicates,
+	}
+
+	if *checkBalanceFlag {
+		cfg, err := resolveSMSConfig(*appKeyFlag, *apiSecretFlag, *baseURLFlag)
+		if err != nil {
+			return err
+		}
+		bc, err := checkBalance(cfg, est, *pointsPerSegment)
+		if err != nil {
+			return reportAPIError(err)
+		}
+		result["required_points"] = bc.Required
+		result["balance"] = bc.Balance
+		result["sufficient"] = bc.Sufficient()
+		if !bc.Sufficient() {
+			progressf("تحذير: %s\n", bc)
+		} else {
+			progressf("%s\n", bc)
+		}
+	}
+	return emitValue(result, est.table())
+}
+
+// balanceCheck compares the points a send needs with the account balance.
+// Points per segment depend on the account's pricing, so the rate is given
+// by the user.
+type balanceCheck struct {
+	Balance  float64
+	Required float64
+}
+
+func (b balanceCheck) Sufficient() bool {
+	return b.Balance >= b.Required
+}
+
+func (b balanceCheck) String() string {
+	verdict := "يكفي"
+	if !b.Sufficient() {
+		verdict = "لا يكفي"
+	}
+	return fmt.Sprintf("الرصيد %s %s، المطلوب %s", formatPoints(b.Balance), verdict, formatPoints(b.Required))
+}
+
+func checkBalance(cfg smsConfig, est smsEstimate, pointsPerSegment float64) (balanceCheck, error) {
+	res, err := cfg.client().Packages(context.Background())
+	if err != nil {
+		return balanceCheck{}, err
+	}
+	return balanceCheck{
+		Balance:  float64(res.TotalBalance),
+		Required: float64(est.Segments) * pointsPerSegment,
+	}, nil
+}
+
+// requireBalance is the --require-balance guard of sms send. When the balance
+// is short it asks for confirmation on an interactive terminal and refuses
+// otherwise; a dry run only warns.
+func requireBalance(cfg smsConfig, est smsEstimate, pointsPerSegment float64, dryRun bool) error {
+	bc, err := checkBalance(cfg, est, pointsPerSegment)
+	if err != nil {
+		return reportAPIError(err)
+	}
+	if bc.Sufficient() {
+		progressf("%s\n", bc)
+		return nil
+	}
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "تحذير: %s\n", bc)
+		return nil
+	}
+	if isTerminal(os.Stdin) && isTerminal(os.Stderr) {
+		if confirm(fmt.Sprintf("%s. هل تريد الإرسال رغم ذلك؟", bc)) {
+			return nil
+		}
+	}
+	return &insufficientBalanceError{Balance: bc.Balance, Required: bc.Required}
+}
+
+func formatPoints(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// smsDryRun is dryRunPrint for SMS sends, with the segment estimate added.
+func smsDryRun(method, endpoint string, payload any, est smsEstimate) error {
+	switch output {
+	case outputQuiet:
+		return nil
+	case outputText:
+		if err := dryRunPrint(method, endpoint, payload); err != nil {
+			return err
+		}
+		fmt.Printf("[dry-run] %s\n", est.Summary())
+		return nil
+	}
+	return prettyPrintJSON(map[string]any{
+		"dry_run":  true,
+		"method":   method,
+		"endpoint": endpoint,
+		"payload":  payload,
+		"estimate": est,
+	})
+}
@@ -0,0 +1,139 @@
+package fourjawaly
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// APIError is returned when the API answers with a non-2xx status, or with a
+// 2xx status whose body reports that the request was rejected.
+type APIError struct {
+	StatusCode int
+	Code       int
+	Message    string
+	Errors     map[string][]string
+	Body       []byte
+}
+
+// Rejected reports whether the request reached the API and got a 2xx status
+// but was refused in the body, as opposed to failing with an HTTP error status.
+func (e *APIError) Rejected() bool {
+	return e.StatusCode >= 200 && e.StatusCode <= 299
+}
+
+// Unauthorized reports whether the credentials were refused.
+func (e *APIError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	fields := make([]string, 0, len(e.Errors))
+	for field := range e.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		msg += fmt.Sprintf("; %s: %s", field, strings.Join(e.Errors[field], ", "))
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, msg)
+}
+
+// NetworkError is returned when no response was received, e.g. on a timeout
+// or a reset connection.
+type NetworkError struct {
+	Err error
+	// Written reports whether the request had been sent in full, so the API
+	// may have acted on it.
+	Written bool
+}
+
+func (e *NetworkError) Error() string {
+	return "network: " + e.Err.Error()
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+type errorBody struct {
+	Code    FlexInt         `json:"code"`
+	Message string          `json:"message"`
+	ErrText string          `json:"err_text"`
+	Errors  json.RawMessage `json:"errors"`
+	Error   *struct {
+		Code    FlexInt `json:"code"`
+		Message string  `json:"message"`
+	} `json:"error"`
+}
+
+// NewAPIError builds an APIError from res, reading whatever error details the
+// body carries.
+func NewAPIError(res *Response) *APIError {
+	e := &APIError{StatusCode: res.StatusCode, Body: res.Body}
+
+	var body errorBody
+	if err := json.Unmarshal(res.Body, &body); err != nil {
+		e.Message = strings.TrimSpace(string(res.Body))
+		return e
+	}
+	e.Code = int(body.Code)
+	e.Message = firstNonEmpty(body.ErrText, body.Message)
+	if body.Error != nil {
+		e.Code = int(body.Error.Code)
+		e.Message = firstNonEmpty(body.Error.Message, e.Message)
+	}
+
+	// "errors" is either {"field": ["msg", ...]} or a flat list of strings.
+	var fields map[string][]string
+	if err := json.Unmarshal(body.Errors, &fields); err == nil && len(fields) > 0 {
+		e.Errors = fields
+	} else {
+		var list []string
+		if err := json.Unmarshal(body.Errors, &list); err == nil && len(list) > 0 {
+			e.Errors = map[string][]string{"errors": list}
+		}
+	}
+	return e
+}
+
+// Meta is embedded in decoded results to keep the response they came from.
+type Meta struct {
+	StatusCode int             `json:"-"`
+	Raw        json.RawMessage `json:"-"`
+}
+
+func (m *Meta) setResponse(res *Response) {
+	m.StatusCode = res.StatusCode
+	m.Raw = res.Body
+}
+
+// Decode checks that res carries a 2xx status and unmarshals its body into v.
+// Unknown fields are ignored. If v embeds Meta it is filled in as well.
+func Decode(res *Response, v any) error {
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return NewAPIError(res)
+	}
+	if err := res.Decode(v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if m, ok := v.(interface{ setResponse(*Response) }); ok {
+		m.setResponse(res)
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package fourjawaly
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// The API is not consistent about quoting numbers, and ids or flags may arrive
+// as strings, numbers, booleans or null depending on the endpoint. These types
+// accept any of those forms so a response never fails to decode over it.
+
+// FlexString decodes a JSON string or number.
+type FlexString string
+
+func (s *FlexString) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*s = ""
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var v string
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*s = FlexString(v)
+		return nil
+	}
+	*s = FlexString(data)
+	return nil
+}
+
+func (s FlexString) String() string {
+	return string(s)
+}
+
+// FlexInt decodes a JSON number, numeric string or boolean.
+type FlexInt int64
+
+func (n *FlexInt) UnmarshalJSON(data []byte) error {
+	f, err := parseFlexNumber(data)
+	if err != nil {
+		return err
+	}
+	*n = FlexInt(f)
+	return nil
+}
+
+// FlexFloat decodes a JSON number, numeric string or boolean.
+type FlexFloat float64
+
+func (n *FlexFloat) UnmarshalJSON(data []byte) error {
+	f, err := parseFlexNumber(data)
+	if err != nil {
+		return err
+	}
+	*n = FlexFloat(f)
+	return nil
+}
+
+func parseFlexNumber(data []byte) (float64, error) {
+	raw := strings.TrimSpace(string(data))
+	switch raw {
+	case "null", `""`, "false":
+		return 0, nil
+	case "true":
+		return 1, nil
+	}
+	if strings.HasPrefix(raw, `"`) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return 0, err
+		}
+		raw = strings.TrimSpace(s)
+		if raw == "" {
+			return 0, nil
+		}
+	}
+	return strconv.ParseFloat(raw, 64)
+}
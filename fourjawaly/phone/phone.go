@@ -0,0 +1,190 @@
+// Package phone normalizes phone numbers to the international format the
+// 4Jawaly APIs expect: E.164 digits without the leading plus, e.g. 966501234567.
+package phone
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultCountry is used for numbers written in national format.
+const DefaultCountry = "SA"
+
+// Country describes how mobile numbers are written in one country.
+type Country struct {
+	ISO         string
+	CallingCode string
+	// Lengths are the allowed lengths of the national number, without the
+	// calling code and without the trunk 0.
+	Lengths []int
+	// Prefixes, when set, are the allowed leading digits of a mobile national number.
+	Prefixes []string
+}
+
+var countries = map[string]Country{
+	"SA": {ISO: "SA", CallingCode: "966", Lengths: []int{9}, Prefixes: []string{"5"}},
+	"AE": {ISO: "AE", CallingCode: "971", Lengths: []int{9}, Prefixes: []string{"5"}},
+	"KW": {ISO: "KW", CallingCode: "965", Lengths: []int{8}, Prefixes: []string{"4", "5", "6", "9"}},
+	"BH": {ISO: "BH", CallingCode: "973", Lengths: []int{8}, Prefixes: []string{"3", "6"}},
+	"QA": {ISO: "QA", CallingCode: "974", Lengths: []int{8}, Prefixes: []string{"3", "5", "6", "7"}},
+	"OM": {ISO: "OM", CallingCode: "968", Lengths: []int{8}, Prefixes: []string{"7", "9"}},
+	"YE": {ISO: "YE", CallingCode: "967", Lengths: []int{9}, Prefixes: []string{"7"}},
+	"IQ": {ISO: "IQ", CallingCode: "964", Lengths: []int{10}, Prefixes: []string{"7"}},
+	"JO": {ISO: "JO", CallingCode: "962", Lengths: []int{9}, Prefixes: []string{"7"}},
+	"SY": {ISO: "SY", CallingCode: "963", Lengths: []int{9}, Prefixes: []string{"9"}},
+	"LB": {ISO: "LB", CallingCode: "961", Lengths: []int{7, 8}, Prefixes: []string{"3", "7", "8"}},
+	"PS": {ISO: "PS", CallingCode: "970", Lengths: []int{9}, Prefixes: []string{"5"}},
+	"EG": {ISO: "EG", CallingCode: "20", Lengths: []int{10}, Prefixes: []string{"1"}},
+	"SD": {ISO: "SD", CallingCode: "249", Lengths: []int{9}, Prefixes: []string{"1", "9"}},
+	"MA": {ISO: "MA", CallingCode: "212", Lengths: []int{9}, Prefixes: []string{"6", "7"}},
+	"DZ": {ISO: "DZ", CallingCode: "213", Lengths: []int{9}, Prefixes: []string{"5", "6", "7"}},
+	"TN": {ISO: "TN", CallingCode: "216", Lengths: []int{8}, Prefixes: []string{"2", "4", "5", "9"}},
+	"PK": {ISO: "PK", CallingCode: "92", Lengths: []int{10}, Prefixes: []string{"3"}},
+	"IN": {ISO: "IN", CallingCode: "91", Lengths: []int{10}, Prefixes: []string{"6", "7", "8", "9"}},
+	"GB": {ISO: "GB", CallingCode: "44", Lengths: []int{10}, Prefixes: []string{"7"}},
+	"US": {ISO: "US", CallingCode: "1", Lengths: []int{10}},
+}
+
+// Lookup returns the country with the given ISO 3166 alpha-2 code.
+func Lookup(iso string) (Country, bool) {
+	c, ok := countries[strings.ToUpper(strings.TrimSpace(iso))]
+	return c, ok
+}
+
+// byCallingCode finds the known country whose calling code prefixes digits.
+func byCallingCode(digits string) (Country, bool) {
+	for n := 3; n >= 1; n-- {
+		if len(digits) <= n {
+			continue
+		}
+		for _, c := range countries {
+			if c.CallingCode == digits[:n] {
+				return c, true
+			}
+		}
+	}
+	return Country{}, false
+}
+
+// Error reports a number that could not be normalized.
+type Error struct {
+	Input  string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("رقم غير صحيح %q: %s", e.Input, e.Reason)
+}
+
+// Normalize converts input to E.164 digits without the plus. Numbers written
+// nationally (with or without the trunk 0) get defaultCountry's calling code;
+// numbers starting with +, 00 or a known calling code are taken as
+// international.
+// Spaces, dashes, dots, parentheses and Arabic-Indic digits are accepted.
+func Normalize(input, defaultCountry string) (string, error) {
+	def, ok := Lookup(defaultCountry)
+	if !ok {
+		return "", fmt.Errorf("دولة غير مدعومة %q", defaultCountry)
+	}
+
+	cleaned, international, err := clean(input)
+	if err != nil {
+		return "", err
+	}
+
+	// Length decides between national and international: a national number
+	// may itself start with the calling code's digits, e.g. 96551234 in KW.
+	var digits string
+	bare := false
+	switch {
+	case international:
+		digits = cleaned
+	case strings.HasPrefix(cleaned, "0"):
+		digits = def.CallingCode + strings.TrimLeft(cleaned, "0")
+	case def.hasLength(len(cleaned)):
+		digits = def.CallingCode + cleaned
+	default:
+		// Already international, for defaultCountry or another one.
+		digits, bare = cleaned, true
+	}
+
+	if len(digits) < 8 {
+		return "", &Error{Input: input, Reason: "الرقم قصير جدًا"}
+	}
+
+	if c, ok := byCallingCode(digits); ok {
+		national := digits[len(c.CallingCode):]
+		// A national number repeated after the code with its trunk 0, e.g. 9660501234567.
+		if !c.hasLength(len(national)) && strings.HasPrefix(national, "0") {
+			national = national[1:]
+			digits = c.CallingCode + national
+		}
+		if !c.hasLength(len(national)) {
+			return "", &Error{Input: input, Reason: fmt.Sprintf("طول الرقم لا يناسب %s", c.ISO)}
+		}
+		if !c.hasPrefix(national) {
+			return "", &Error{Input: input, Reason: fmt.Sprintf("ليس رقم جوال في %s", c.ISO)}
+		}
+		return digits, nil
+	}
+
+	// Without + or 00 only a known calling code, whose lengths were checked
+	// above, marks a number as international: anything else is more likely
+	// a mistyped national number than a number abroad.
+	if bare {
+		return "", &Error{Input: input, Reason: fmt.Sprintf("طول الرقم لا يناسب %s (اكتب الأرقام الدولية الأخرى بـ + أو 00)", def.ISO)}
+	}
+	if len(digits) < 8 || len(digits) > 15 {
+		return "", &Error{Input: input, Reason: "الرقم الدولي يجب أن يكون بين 8 و 15 خانة"}
+	}
+	return digits, nil
+}
+
+// clean strips formatting characters and international prefixes.
+func clean(input string) (digits string, international bool, err error) {
+	var sb strings.Builder
+	for i, r := range strings.TrimSpace(input) {
+		switch {
+		case r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		case r >= '٠' && r <= '٩':
+			sb.WriteRune('0' + (r - '٠'))
+		case r >= '۰' && r <= '۹':
+			sb.WriteRune('0' + (r - '۰'))
+		case r == '+' && i == 0:
+			international = true
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '\u00a0':
+		default:
+			return "", false, &Error{Input: input, Reason: fmt.Sprintf("حرف غير مسموح %q", r)}
+		}
+	}
+	digits = sb.String()
+	if strings.HasPrefix(digits, "00") && !international {
+		digits, international = digits[2:], true
+	}
+	if digits == "" {
+		return "", false, &Error{Input: input, Reason: "لا يحتوي أرقامًا"}
+	}
+	return digits, international, nil
+}
+
+func (c Country) hasLength(n int) bool {
+	for _, l := range c.Lengths {
+		if l == n {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Country) hasPrefix(national string) bool {
+	if len(c.Prefixes) == 0 {
+		return true
+	}
+	for _, p := range c.Prefixes {
+		if strings.HasPrefix(national, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeCountries(t *testing.T) {
+	// One valid mobile number per supported country, in national format
+	// without the trunk 0.
+	national := map[string][]string{
+		"SA": {"501234567"},
+		"AE": {"501234567"},
+		"KW": {"51234567", "96551234"},
+		"BH": {"36001234"},
+		"QA": {"33123456"},
+		"OM": {"92123456", "96812345"},
+		"YE": {"771234567"},
+		"IQ": {"7901234567"},
+		"JO": {"791234567"},
+		"SY": {"944123456", "963123456"},
+		"LB": {"3123456", "71123456"},
+		"PS": {"591234567"},
+		"EG": {"1001234567"},
+		"SD": {"912345678"},
+		"MA": {"612345678"},
+		"DZ": {"551234567"},
+		"TN": {"20123456", "21612345"},
+		"PK": {"3001234567"},
+		"IN": {"9876543210", "9198765432"},
+		"GB": {"7400123456"},
+		"US": {"2025550123"},
+	}
+	if len(national) != len(countries) {
+		t.Fatalf("test covers %d countries, package supports %d", len(national), len(countries))
+	}
+
+	for iso, numbers := range national {
+		c, _ := Lookup(iso)
+		for _, n := range numbers {
+			want := c.CallingCode + n
+			for _, input := range []string{
+				n,
+				"0" + n,
+				"+" + c.CallingCode + n,
+				"00" + c.CallingCode + n,
+				c.CallingCode + n,
+				"+" + c.CallingCode + " " + n[:2] + "-" + n[2:],
+			} {
+				got, err := Normalize(input, iso)
+				if err != nil || got != want {
+					t.Errorf("Normalize(%q, %s) = %q, %v, want %q", input, iso, got, err, want)
+				}
+			}
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input   string
+		country string
+		want    string
+		wantErr bool
+	}{
+		// National numbers that begin with the calling code's digits.
+		{input: "96551234", country: "KW", want: "96596551234"},
+		{input: "96596551234", country: "KW", want: "96596551234"},
+		{input: "9198765432", country: "IN", want: "919198765432"},
+
+		// International numbers of another country than the default.
+		{input: "96551234567", country: "SA", want: "96551234567"},
+		{input: "96551234", country: "SA", wantErr: true},
+		{input: "+971501234567", country: "SA", want: "971501234567"},
+		{input: "00201001234567", country: "SA", want: "201001234567"},
+		{input: "+4915112345678", country: "SA", want: "4915112345678"},
+
+		// Bare digits are international only for a known calling code with a
+		// valid length; otherwise they are a mistyped national number.
+		{input: "971501234567", country: "SA", want: "971501234567"},
+		{input: "15551234567", country: "SA", want: "15551234567"},
+		{input: "5012345678", country: "SA", wantErr: true},
+		{input: "50123456789", country: "SA", wantErr: true},
+		{input: "4915112345678", country: "SA", wantErr: true},
+		{input: "97150123456", country: "SA", wantErr: true},
+		{input: "5012345678", country: "US", want: "15012345678"},
+		{input: "501234567", country: "SA", want: "966501234567"},
+
+		// Formatting.
+		{input: "(050) 123.45 67", country: "SA", want: "966501234567"},
+		{input: "٠٥٠١٢٣٤٥٦٧", country: "SA", want: "966501234567"},
+		{input: "۰۵۰۱۲۳۴۵۶۷", country: "SA", want: "966501234567"},
+		{input: "9660501234567", country: "SA", want: "966501234567"},
+		{input: "sa", country: "sa", wantErr: true},
+
+		// Invalid numbers.
+		{input: "12345", country: "SA", wantErr: true},
+		{input: "0401234567", country: "SA", wantErr: true},
+		{input: "96650123456", country: "SA", wantErr: true},
+		{input: "+9665012345678", country: "SA", wantErr: true},
+		{input: "0501234567x", country: "SA", wantErr: true},
+		{input: "---", country: "SA", wantErr: true},
+		{input: "0501234567", country: "XX", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.input, tt.country)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Normalize(%q, %s) = %q, want an error", tt.input, tt.country, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q, %s) = %q, %v, want %q", tt.input, tt.country, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizeErrorType(t *testing.T) {
+	_, err := Normalize("12345", "SA")
+	var phoneErr *Error
+	if !errors.As(err, &phoneErr) || phoneErr.Input != "12345" {
+		t.Errorf("Normalize error = %#v, want *Error for the input", err)
+	}
+}
@@ -0,0 +1,100 @@
+package fourjawaly
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how Transport retries transient failures: network
+// errors, 429 and 5xx. Other 4xx responses are never retried.
+//
+// POSTs, which send messages, are only retried when the failure proves the
+// API did not act on them: a 429, or a network error before the request was
+// written. A 5xx or a connection lost after the request went out may follow a
+// delivered message, so retrying it needs RetryUnsafe.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry; it doubles on every retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff. A Retry-After header is honoured
+	// even when longer, up to MaxRetryAfter.
+	MaxDelay time.Duration
+	// MaxRetryAfter is the longest Retry-After that is waited for; a longer
+	// one ends the retries and the response is returned as is.
+	MaxRetryAfter time.Duration
+	// Jitter is the fraction (0..1) of each backoff that is randomized.
+	Jitter float64
+	// RetryUnsafe also retries POSTs after failures that may have reached
+	// the API. It can deliver a message twice.
+	RetryUnsafe bool
+	// OnRetry, if set, is called before waiting for each retry.
+	OnRetry func(attempt int, delay time.Duration, reason error)
+}
+
+// DefaultRetryPolicy is used by clients created with NewClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:   3,
+	BaseDelay:     500 * time.Millisecond,
+	MaxDelay:      10 * time.Second,
+	MaxRetryAfter: 2 * time.Minute,
+	Jitter:        0.5,
+}
+
+// retryable reports whether a response or error is worth another attempt.
+// A per-attempt timeout is retried; a cancelled or expired ctx is not.
+func (p RetryPolicy) retryable(ctx context.Context, method string, res *Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	safe := method != http.MethodPost || p.RetryUnsafe
+	if err != nil {
+		var netErr *NetworkError
+		return errors.As(err, &netErr) && (safe || !netErr.Written)
+	}
+	return res.StatusCode == http.StatusTooManyRequests || (res.StatusCode >= 500 && safe)
+}
+
+// backoff returns the wait before retry number n (1-based).
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := p.BaseDelay << (n - 1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if j := min(max(p.Jitter, 0), 1); j > 0 {
+		d -= time.Duration(j * rand.Float64() * float64(d))
+	}
+	return d
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,120 @@
+package fourjawaly
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testRetry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+func TestTransportRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		unsafe   bool
+		attempts int
+	}{
+		{name: "GET 5xx", method: http.MethodGet, status: http.StatusBadGateway, attempts: 3},
+		{name: "GET 429", method: http.MethodGet, status: http.StatusTooManyRequests, attempts: 3},
+		{name: "POST 429", method: http.MethodPost, status: http.StatusTooManyRequests, attempts: 3},
+		{name: "POST 5xx", method: http.MethodPost, status: http.StatusInternalServerError, attempts: 1},
+		{name: "POST 5xx unsafe", method: http.MethodPost, status: http.StatusInternalServerError, unsafe: true, attempts: 3},
+		{name: "POST 4xx", method: http.MethodPost, status: http.StatusUnprocessableEntity, attempts: 1},
+		{name: "GET 4xx", method: http.MethodGet, status: http.StatusBadRequest, attempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			p := testRetry
+			p.RetryUnsafe = tt.unsafe
+			tr := Transport{Retry: p}
+			res, err := tr.Do(context.Background(), tt.method, srv.URL, map[string]string{"a": "b"})
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			if res.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", res.StatusCode, tt.status)
+			}
+			if got := int(calls.Load()); got != tt.attempts {
+				t.Errorf("attempts = %d, want %d", got, tt.attempts)
+			}
+		})
+	}
+}
+
+// TestTransportRetryAfterWrite drops the connection once the request has
+// been read: the API may have acted on it, so a POST must not be repeated.
+func TestTransportRetryAfterWrite(t *testing.T) {
+	for _, tt := range []struct {
+		method   string
+		unsafe   bool
+		attempts int
+	}{
+		{method: http.MethodPost, attempts: 1},
+		{method: http.MethodPost, unsafe: true, attempts: 3},
+		{method: http.MethodGet, attempts: 3},
+	} {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			io.ReadAll(r.Body)
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+		}))
+
+		p := testRetry
+		p.RetryUnsafe = tt.unsafe
+		tr := Transport{Retry: p}
+		_, err := tr.Do(context.Background(), tt.method, srv.URL, map[string]string{"a": "b"})
+		srv.Close()
+
+		var netErr *NetworkError
+		if !errors.As(err, &netErr) || !netErr.Written {
+			t.Errorf("%s unsafe=%v: err = %#v, want a NetworkError after the request was written", tt.method, tt.unsafe, err)
+		}
+		if got := int(calls.Load()); got != tt.attempts {
+			t.Errorf("%s unsafe=%v: attempts = %d, want %d", tt.method, tt.unsafe, got, tt.attempts)
+		}
+	}
+}
+
+// TestTransportRetryBeforeWrite fails to connect at all: nothing reached the
+// API, so even a POST is retried.
+func TestTransportRetryBeforeWrite(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	var retries int
+	p := testRetry
+	p.OnRetry = func(int, time.Duration, error) { retries++ }
+	tr := Transport{Retry: p}
+	_, err = tr.Do(context.Background(), http.MethodPost, "http://"+addr, map[string]string{"a": "b"})
+
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) || netErr.Written {
+		t.Errorf("err = %#v, want a NetworkError before the request was written", err)
+	}
+	if retries != testRetry.MaxAttempts-1 {
+		t.Errorf("retries = %d, want %d", retries, testRetry.MaxAttempts-1)
+	}
+}
@@ -0,0 +1,134 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"fourjawaly-cli/fourjawaly"
+)
+
+// PackagesQuery filters ListPackages. Zero fields keep the endpoint defaults.
+type PackagesQuery struct {
+	// IsActive filters on the is_active flag; nil lists every package.
+	IsActive *bool
+	Page     int
+	PageSize int
+}
+
+// SendersQuery filters ListSenders. Zero fields keep the endpoint defaults.
+type SendersQuery struct {
+	// Status filters on the sender status (1 is approved); nil lists every
+	// sender.
+	Status   *int
+	Page     int
+	PageSize int
+}
+
+// Packages lists the first page of the account's active packages, newest
+// first.
+func (c *Client) Packages(ctx context.Context) (*PackagesResult, error) {
+	active := true
+	return c.ListPackages(ctx, PackagesQuery{IsActive: &active, Page: 1, PageSize: 10})
+}
+
+// ListPackages lists one page of the account's packages, newest first.
+func (c *Client) ListPackages(ctx context.Context, q PackagesQuery) (*PackagesResult, error) {
+	query := url.Values{}
+	if q.IsActive != nil {
+		query.Set("is_active", boolParam(*q.IsActive))
+	}
+	query.Set("order_by", "id")
+	query.Set("order_by_type", "desc")
+	setPage(query, q.Page, q.PageSize)
+	query.Set("return_collection", "1")
+
+	var out PackagesResult
+	if err := c.get(ctx, "/account/area/me/packages", query, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Senders lists the first page of the account's approved sender names.
+func (c *Client) Senders(ctx context.Context) (*SendersResult, error) {
+	approved := 1
+	return c.ListSenders(ctx, SendersQuery{Status: &approved, Page: 1, PageSize: 50})
+}
+
+// ListSenders lists one page of the account's sender names.
+func (c *Client) ListSenders(ctx context.Context, q SendersQuery) (*SendersResult, error) {
+	query := url.Values{}
+	setPage(query, q.Page, q.PageSize)
+	if q.Status != nil {
+		query.Set("status", strconv.Itoa(*q.Status))
+	}
+	query.Set("return_collection", "1")
+
+	var out SendersResult
+	if err := c.get(ctx, "/account/area/senders", query, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FindSender looks name up among all of the account's sender names,
+// whatever their status, ignoring case. It returns nil if there is none.
+func (c *Client) FindSender(ctx context.Context, name string) (*Sender, error) {
+	pager := NewPager(func(ctx context.Context, page int) (Collection[Sender], error) {
+		res, err := c.ListSenders(ctx, SendersQuery{Page: page, PageSize: 50})
+		if err != nil {
+			return Collection[Sender]{}, err
+		}
+		return res.Senders, nil
+	})
+	for {
+		page, ok, err := pager.Next(ctx)
+		if err != nil || !ok {
+			return nil, err
+		}
+		for i := range page.Data {
+			if strings.EqualFold(page.Data[i].SenderName, name) {
+				return &page.Data[i], nil
+			}
+		}
+	}
+}
+
+// SenderRequest is the body of a new sender name request.
+type SenderRequest struct {
+	SenderName string `json:"sender_name"`
+	Note       string `json:"note,omitempty"`
+}
+
+// RequestSender submits a new sender name for approval. The name shows up in
+// ListSenders as pending until it is reviewed.
+func (c *Client) RequestSender(ctx context.Context, req SenderRequest) (*SenderRequestResult, error) {
+	res, err := c.transport.Do(ctx, http.MethodPost, c.url("/account/area/senders", nil), req)
+	if err != nil {
+		return nil, err
+	}
+	var out SenderRequestResult
+	if err := fourjawaly.Decode(res, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func setPage(query url.Values, page, pageSize int) {
+	if pageSize > 0 {
+		query.Set("page_size", strconv.Itoa(pageSize))
+	}
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+}
+
+func boolParam(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
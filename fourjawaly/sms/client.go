@@ -0,0 +1,83 @@
+// Package sms is a client for the 4Jawaly SMS API.
+package sms
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"fourjawaly-cli/fourjawaly"
+)
+
+// DefaultBaseURL is the production SMS API.
+const DefaultBaseURL = "https://api-sms.4jawaly.com/api/v1"
+
+// Client talks to the SMS API with a single set of credentials.
+type Client struct {
+	baseURL   string
+	transport fourjawaly.Transport
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL overrides DefaultBaseURL.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if u := strings.TrimRight(strings.TrimSpace(baseURL), "/"); u != "" {
+			c.baseURL = u
+		}
+	}
+}
+
+// WithHTTPClient overrides fourjawaly.DefaultHTTPClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.transport.HTTPClient = hc
+	}
+}
+
+// WithRetry overrides fourjawaly.DefaultRetryPolicy; pass the zero
+// RetryPolicy to disable retries.
+func WithRetry(p fourjawaly.RetryPolicy) Option {
+	return func(c *Client) {
+		c.transport.Retry = p
+	}
+}
+
+// NewClient returns a Client authenticated with appKey and apiSecret.
+func NewClient(appKey, apiSecret string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: DefaultBaseURL,
+		transport: fourjawaly.Transport{
+			Credentials: fourjawaly.Credentials{AppKey: appKey, APISecret: apiSecret},
+			Retry:       fourjawaly.DefaultRetryPolicy,
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the API root the client sends to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+func (c *Client) url(path string, query url.Values) string {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	return endpoint
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	res, err := c.transport.Do(ctx, http.MethodGet, c.url(path, query), nil)
+	if err != nil {
+		return err
+	}
+	return fourjawaly.Decode(res, out)
+}
@@ -0,0 +1,370 @@
+package sms
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"fourjawaly-cli/fourjawaly"
+)
+
+// captured is what the test server saw of a request.
+type captured struct {
+	method, path, auth, contentType string
+	query                           url.Values
+	body                            any
+}
+
+// testServer answers each request with the next of responses, repeating the
+// last one, and records what it received.
+func testServer(t *testing.T, responses ...response) (*httptest.Server, *[]captured) {
+	t.Helper()
+	var got []captured
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := captured{
+			method:      r.Method,
+			path:        r.URL.Path,
+			auth:        r.Header.Get("Authorization"),
+			contentType: r.Header.Get("Content-Type"),
+			query:       r.URL.Query(),
+		}
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			if err := json.Unmarshal(data, &c.body); err != nil {
+				t.Errorf("request body %q: %v", data, err)
+			}
+		}
+		res := responses[min(len(got), len(responses)-1)]
+		got = append(got, c)
+		w.WriteHeader(res.status)
+		io.WriteString(w, res.body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+type response struct {
+	status int
+	body   string
+}
+
+var okResponse = response{http.StatusOK, `{"code":200}`}
+
+func jsonValue(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("bad test JSON %q: %v", s, err)
+	}
+	return v
+}
+
+func checkAuth(t *testing.T, c captured) {
+	t.Helper()
+	if want := "Basic " + base64.StdEncoding.EncodeToString([]byte("key:secret")); c.auth != want {
+		t.Errorf("Authorization = %q, want %q", c.auth, want)
+	}
+}
+
+func TestSendRequest(t *testing.T) {
+	srv, got := testServer(t, okResponse)
+	c := NewClient("key", "secret", WithBaseURL(srv.URL+"/api/v1/"))
+	req := SendRequest{Messages: []Message{
+		{Text: "مرحبا", Numbers: []string{"966501234567", "966507654321"}, Sender: "4jawaly"},
+		{Text: "hi", Numbers: []string{"966500000000"}, Sender: "4jawaly"},
+	}}
+	if _, err := c.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	r := (*got)[0]
+	if r.method != http.MethodPost || r.path != "/api/v1/account/area/sms/send" {
+		t.Errorf("request = %s %s, want POST /api/v1/account/area/sms/send", r.method, r.path)
+	}
+	checkAuth(t, r)
+	if r.contentType != "application/json" {
+		t.Errorf("Content-Type = %q", r.contentType)
+	}
+	want := jsonValue(t, `{"messages":[`+
+		`{"text":"مرحبا","numbers":["966501234567","966507654321"],"sender":"4jawaly"},`+
+		`{"text":"hi","numbers":["966500000000"],"sender":"4jawaly"}]}`)
+	if !reflect.DeepEqual(r.body, want) {
+		t.Errorf("body =\n%v\nwant\n%v", r.body, want)
+	}
+}
+
+func TestAccountRequests(t *testing.T) {
+	tests := []struct {
+		name  string
+		call  func(context.Context, *Client) error
+		path  string
+		query string
+	}{
+		{
+			name: "packages",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.Packages(ctx)
+				return err
+			},
+			path:  "/account/area/me/packages",
+			query: "is_active=1&order_by=id&order_by_type=desc&page=1&page_size=10&return_collection=1",
+		},
+		{
+			name: "senders",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.Senders(ctx)
+				return err
+			},
+			path:  "/account/area/senders",
+			query: "page=1&page_size=50&status=1&return_collection=1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, got := testServer(t, okResponse)
+			if err := tt.call(context.Background(), NewClient("key", "secret", WithBaseURL(srv.URL))); err != nil {
+				t.Fatalf("call: %v", err)
+			}
+			r := (*got)[0]
+			if r.method != http.MethodGet || r.path != tt.path {
+				t.Errorf("request = %s %s, want GET %s", r.method, r.path, tt.path)
+			}
+			checkAuth(t, r)
+			if want, _ := url.ParseQuery(tt.query); !reflect.DeepEqual(r.query, want) {
+				t.Errorf("query = %v, want %v", r.query, want)
+			}
+			if r.body != nil {
+				t.Errorf("body = %v, want none", r.body)
+			}
+		})
+	}
+}
+
+func TestSendResponses(t *testing.T) {
+	tests := []struct {
+		name     string
+		res      response
+		jobID    string // empty when no result is expected
+		message  string // empty when no error is expected
+		errors   map[string][]string
+		rejected bool
+	}{
+		{name: "accepted", res: response{http.StatusOK, `{"code":200,"job_id":"17","messages":[{"inserted_numbers":2}]}`}, jobID: "17"},
+		{
+			name:     "err_text in a 200",
+			res:      response{http.StatusOK, `{"code":200,"job_id":18,"messages":[{"inserted_numbers":0,"err_text":"رصيد غير كاف"}]}`},
+			jobID:    "18",
+			message:  "رصيد غير كاف",
+			rejected: true,
+		},
+		{
+			name:    "validation errors",
+			res:     response{http.StatusUnprocessableEntity, `{"code":422,"message":"invalid","errors":{"messages.0.sender":["sender not found"]}}`},
+			message: "invalid",
+			errors:  map[string][]string{"messages.0.sender": {"sender not found"}},
+		},
+		{
+			name:    "error list",
+			res:     response{http.StatusBadRequest, `{"message":"bad","errors":["numbers empty"]}`},
+			message: "bad",
+			errors:  map[string][]string{"errors": {"numbers empty"}},
+		},
+		{name: "refused credentials", res: response{http.StatusUnauthorized, `{"message":"Unauthenticated."}`}, message: "Unauthenticated."},
+		{name: "plain text error", res: response{http.StatusNotFound, "Not Found\n"}, message: "Not Found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := testServer(t, tt.res)
+			c := NewClient("key", "secret", WithBaseURL(srv.URL))
+			res, err := c.Send(context.Background(), NewSendRequest("hi", "S", []string{"966501234567"}))
+			if (res != nil) != (tt.jobID != "") {
+				t.Fatalf("Send result = %+v, want one: %v", res, tt.jobID != "")
+			}
+			if res != nil && (res.JobID.String() != tt.jobID || res.StatusCode != tt.res.status) {
+				t.Errorf("result = job %q (HTTP %d), want job %q (HTTP %d)", res.JobID, res.StatusCode, tt.jobID, tt.res.status)
+			}
+			if tt.message == "" {
+				if err != nil {
+					t.Fatalf("Send: %v", err)
+				}
+				return
+			}
+			var apiErr *fourjawaly.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Send error = %#v, want a *fourjawaly.APIError", err)
+			}
+			if apiErr.StatusCode != tt.res.status || apiErr.Message != tt.message || !reflect.DeepEqual(apiErr.Errors, tt.errors) {
+				t.Errorf("APIError = HTTP %d, %q, %v, want HTTP %d, %q, %v",
+					apiErr.StatusCode, apiErr.Message, apiErr.Errors, tt.res.status, tt.message, tt.errors)
+			}
+			if apiErr.Rejected() != tt.rejected {
+				t.Errorf("Rejected = %v, want %v", apiErr.Rejected(), tt.rejected)
+			}
+		})
+	}
+}
+
+func TestAccountResults(t *testing.T) {
+	ctx := context.Background()
+
+	srv, _ := testServer(t, response{http.StatusOK, `{"code":200,"total_balance":"1500.5","items":{"current_page":1,"last_page":1,"data":[` +
+		`{"id":7,"package_points":2000,"current_points":"1500.5","expire_at":"2027-01-01","is_active":1}]}}`})
+	packages, err := NewClient("key", "secret", WithBaseURL(srv.URL)).Packages(ctx)
+	if err != nil {
+		t.Fatalf("Packages: %v", err)
+	}
+	if packages.TotalBalance != 1500.5 || len(packages.Packages.Data) != 1 || packages.Packages.Data[0].CurrentPoints != 1500.5 {
+		t.Errorf("Packages = %+v", packages)
+	}
+
+	for _, body := range []string{
+		`{"code":200,"items":{"data":[{"id":1,"sender_name":"4jawaly","status":1}]}}`,
+		`{"code":200,"collection":[{"id":1,"sender_name":"4jawaly","status":"1"}]}`,
+	} {
+		srv, _ := testServer(t, response{http.StatusOK, body})
+		senders, err := NewClient("key", "secret", WithBaseURL(srv.URL)).Senders(ctx)
+		if err != nil {
+			t.Fatalf("Senders: %v", err)
+		}
+		if got := senders.Senders.Data; len(got) != 1 || got[0].SenderName != "4jawaly" || got[0].Status != 1 {
+			t.Errorf("Senders(%s) = %+v", body, got)
+		}
+	}
+
+	srv, _ = testServer(t, response{http.StatusForbidden, `{"code":403,"message":"Forbidden"}`})
+	_, err = NewClient("key", "secret", WithBaseURL(srv.URL)).Packages(ctx)
+	var apiErr *fourjawaly.APIError
+	if !errors.As(err, &apiErr) || !apiErr.Unauthorized() {
+		t.Errorf("Packages error = %v, want an unauthorized *fourjawaly.APIError", err)
+	}
+}
+
+func TestListQueries(t *testing.T) {
+	active, inactive, pending := true, false, 0
+	tests := []struct {
+		name  string
+		call  func(context.Context, *Client) error
+		query string
+	}{
+		{
+			name: "every package",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.ListPackages(ctx, PackagesQuery{})
+				return err
+			},
+			query: "order_by=id&order_by_type=desc&return_collection=1",
+		},
+		{
+			name: "active packages, page 3",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.ListPackages(ctx, PackagesQuery{IsActive: &active, Page: 3, PageSize: 25})
+				return err
+			},
+			query: "is_active=1&order_by=id&order_by_type=desc&page=3&page_size=25&return_collection=1",
+		},
+		{
+			name: "inactive packages",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.ListPackages(ctx, PackagesQuery{IsActive: &inactive})
+				return err
+			},
+			query: "is_active=0&order_by=id&order_by_type=desc&return_collection=1",
+		},
+		{
+			name: "every sender",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.ListSenders(ctx, SendersQuery{PageSize: 10})
+				return err
+			},
+			query: "page_size=10&return_collection=1",
+		},
+		{
+			name: "pending senders, page 2",
+			call: func(ctx context.Context, c *Client) error {
+				_, err := c.ListSenders(ctx, SendersQuery{Status: &pending, Page: 2})
+				return err
+			},
+			query: "page=2&status=0&return_collection=1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, got := testServer(t, okResponse)
+			if err := tt.call(context.Background(), NewClient("key", "secret", WithBaseURL(srv.URL))); err != nil {
+				t.Fatalf("call: %v", err)
+			}
+			if want, _ := url.ParseQuery(tt.query); !reflect.DeepEqual((*got)[0].query, want) {
+				t.Errorf("query = %v, want %v", (*got)[0].query, want)
+			}
+		})
+	}
+}
+
+func TestFindSender(t *testing.T) {
+	pages := []response{
+		{http.StatusOK, `{"items":{"current_page":1,"last_page":2,"data":[{"id":1,"sender_name":"Shop","status":0}]}}`},
+		{http.StatusOK, `{"items":{"current_page":2,"last_page":2,"data":[{"id":2,"sender_name":"4Jawaly","status":1}]}}`},
+	}
+	tests := []struct {
+		name  string
+		find  string
+		id    int // 0 when no sender is expected
+		pages int
+	}{
+		{name: "first page", find: "shop", id: 1, pages: 1},
+		{name: "later page, ignoring case", find: "4JAWALY", id: 2, pages: 2},
+		{name: "missing", find: "other", pages: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, got := testServer(t, pages...)
+			s, err := NewClient("key", "secret", WithBaseURL(srv.URL)).FindSender(context.Background(), tt.find)
+			if err != nil {
+				t.Fatalf("FindSender: %v", err)
+			}
+			if (s != nil) != (tt.id != 0) || s != nil && int(s.ID) != tt.id {
+				t.Errorf("FindSender(%q) = %+v, want id %d", tt.find, s, tt.id)
+			}
+			if len(*got) != tt.pages {
+				t.Fatalf("pages fetched = %d, want %d", len(*got), tt.pages)
+			}
+			for i, r := range *got {
+				if want, _ := url.ParseQuery(fmt.Sprintf("page=%d&page_size=50&return_collection=1", i+1)); !reflect.DeepEqual(r.query, want) {
+					t.Errorf("query %d = %v, want %v (no status filter)", i+1, r.query, want)
+				}
+			}
+		})
+	}
+
+	srv, _ := testServer(t, response{http.StatusUnauthorized, `{"message":"Unauthenticated."}`})
+	_, err := NewClient("key", "secret", WithBaseURL(srv.URL)).FindSender(context.Background(), "Shop")
+	var apiErr *fourjawaly.APIError
+	if !errors.As(err, &apiErr) || !apiErr.Unauthorized() {
+		t.Errorf("FindSender error = %v, want an unauthorized *fourjawaly.APIError", err)
+	}
+}
+
+func TestRequestSender(t *testing.T) {
+	srv, got := testServer(t, response{http.StatusOK, `{"message":"تم الطلب","item":{"id":9,"sender_name":"Shop","status":0}}`})
+	res, err := NewClient("key", "secret", WithBaseURL(srv.URL)).RequestSender(context.Background(), SenderRequest{SenderName: "Shop"})
+	if err != nil {
+		t.Fatalf("RequestSender: %v", err)
+	}
+	if res.Sender == nil || res.Sender.SenderName != "Shop" || res.Sender.State() != "pending" {
+		t.Errorf("RequestSender = %+v", res)
+	}
+	r := (*got)[0]
+	if r.method != http.MethodPost || r.path != "/account/area/senders" {
+		t.Errorf("request = %s %s, want POST /account/area/senders", r.method, r.path)
+	}
+	checkAuth(t, r)
+	if want := jsonValue(t, `{"sender_name":"Shop"}`); !reflect.DeepEqual(r.body, want) {
+		t.Errorf("body = %v, want %v", r.body, want)
+	}
+}
@@ -0,0 +1,169 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"fourjawaly-cli/fourjawaly"
+)
+
+// MessageQuery filters Messages. Zero fields are left out of the request.
+type MessageQuery struct {
+	JobID  string
+	Number string
+	Sender string
+	// From and To bound the send date, both inclusive; only the date part
+	// is used.
+	From     time.Time
+	To       time.Time
+	Page     int
+	PageSize int
+}
+
+func (q MessageQuery) values() url.Values {
+	query := url.Values{}
+	if q.JobID != "" {
+		query.Set("job_id", q.JobID)
+	}
+	if q.Number != "" {
+		query.Set("number", q.Number)
+	}
+	if q.Sender != "" {
+		query.Set("sender_name", q.Sender)
+	}
+	if !q.From.IsZero() {
+		query.Set("date_from", q.From.Format(time.DateOnly))
+	}
+	if !q.To.IsZero() {
+		query.Set("date_to", q.To.Format(time.DateOnly))
+	}
+	setPage(query, q.Page, q.PageSize)
+	query.Set("order_by", "id")
+	query.Set("order_by_type", "desc")
+	query.Set("return_collection", "1")
+	return query
+}
+
+// Messages lists sent messages, one entry per number, from the account area.
+func (c *Client) Messages(ctx context.Context, q MessageQuery) (*MessagesResult, error) {
+	var out MessagesResult
+	if err := c.get(ctx, "/account/area/sms/messages", q.values(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeliveryState is the normalized delivery status of one number.
+type DeliveryState string
+
+const (
+	StatePending   DeliveryState = "pending"   // accepted, not yet handed to the operator
+	StateSent      DeliveryState = "sent"      // handed to the operator, no receipt yet
+	StateDelivered DeliveryState = "delivered" // delivery receipt received
+	StateFailed    DeliveryState = "failed"    // rejected, undeliverable or expired
+	StateUnknown   DeliveryState = "unknown"   // a status this package does not recognize
+)
+
+// Final reports whether the state can no longer change.
+func (s DeliveryState) Final() bool {
+	return s == StateDelivered || s == StateFailed
+}
+
+// deliveryStates maps the status words used by the API and by the operators'
+// delivery receipts.
+var deliveryStates = map[string]DeliveryState{
+	"pending":     StatePending,
+	"queued":      StatePending,
+	"scheduled":   StatePending,
+	"new":         StatePending,
+	"sent":        StateSent,
+	"submitted":   StateSent,
+	"enroute":     StateSent,
+	"accepted":    StateSent,
+	"delivered":   StateDelivered,
+	"delivrd":     StateDelivered,
+	"success":     StateDelivered,
+	"failed":      StateFailed,
+	"undelivered": StateFailed,
+	"undeliv":     StateFailed,
+	"rejected":    StateFailed,
+	"rejectd":     StateFailed,
+	"expired":     StateFailed,
+	"deleted":     StateFailed,
+	"canceled":    StateFailed,
+	"cancelled":   StateFailed,
+}
+
+// SentMessage is one number of a past send.
+type SentMessage struct {
+	ID          fourjawaly.FlexString `json:"id"`
+	JobID       fourjawaly.FlexString `json:"job_id"`
+	Number      fourjawaly.FlexString `json:"number"`
+	SenderName  string                `json:"sender_name"`
+	Text        string                `json:"message"`
+	Status      fourjawaly.FlexString `json:"status"`
+	StatusText  string                `json:"status_text"`
+	CreatedAt   string                `json:"created_at"`
+	DeliveredAt string                `json:"delivered_at"`
+}
+
+// UnmarshalJSON also accepts the alternative key names some endpoints use
+// for the number, text and status.
+func (m *SentMessage) UnmarshalJSON(data []byte) error {
+	type plain SentMessage
+	var body struct {
+		plain
+		Mobile    fourjawaly.FlexString `json:"mobile"`
+		Phone     fourjawaly.FlexString `json:"phone"`
+		Sender    string                `json:"sender"`
+		Body      string                `json:"text"`
+		DLRStatus string                `json:"dlr_status"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+	*m = SentMessage(body.plain)
+	m.Number = fourjawaly.FlexString(firstNonEmpty(m.Number.String(), body.Mobile.String(), body.Phone.String()))
+	m.SenderName = firstNonEmpty(m.SenderName, body.Sender)
+	m.Text = firstNonEmpty(m.Text, body.Body)
+	m.StatusText = firstNonEmpty(m.StatusText, body.DLRStatus)
+	return nil
+}
+
+// State normalizes StatusText, or Status when it is a word rather than a
+// code.
+func (m SentMessage) State() DeliveryState {
+	for _, s := range []string{m.StatusText, m.Status.String()} {
+		if state, ok := deliveryStates[strings.ToLower(strings.TrimSpace(s))]; ok {
+			return state
+		}
+	}
+	return StateUnknown
+}
+
+// MessagesResult is the decoded response of Messages.
+type MessagesResult struct {
+	fourjawaly.Meta
+	Messages Collection[SentMessage] `json:"-"`
+}
+
+func (r *MessagesResult) UnmarshalJSON(data []byte) error {
+	var body collectionBody[SentMessage]
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+	r.Messages = body.page()
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package sms
+
+import "context"
+
+// PageFunc fetches one page of a return_collection listing.
+type PageFunc[T any] func(ctx context.Context, page int) (Collection[T], error)
+
+// Pager walks the pages of a listing in order:
+//
+//	pager := sms.NewPager(fetch)
+//	for {
+//		page, ok, err := pager.Next(ctx)
+//		if err != nil || !ok {
+//			break
+//		}
+//		...
+//	}
+type Pager[T any] struct {
+	fetch PageFunc[T]
+	next  int
+	done  bool
+}
+
+// NewPager returns a Pager starting at page 1.
+func NewPager[T any](fetch PageFunc[T]) *Pager[T] {
+	return &Pager[T]{fetch: fetch, next: 1}
+}
+
+// Next fetches the next page. ok is false once the last page has been
+// returned.
+func (p *Pager[T]) Next(ctx context.Context) (page Collection[T], ok bool, err error) {
+	if p.done {
+		return Collection[T]{}, false, nil
+	}
+	requested := p.next
+	page, err = p.fetch(ctx, requested)
+	if err != nil {
+		p.done = true
+		return Collection[T]{}, false, err
+	}
+	p.next++
+	// A listing without pagination fields has a single page; a server that
+	// answers with an earlier page than asked for would loop forever.
+	if len(page.Data) == 0 || int(page.CurrentPage) >= int(page.LastPage) || int(page.CurrentPage) < requested {
+		p.done = true
+	}
+	return page, true, nil
+}
+
+// All returns the items of every remaining page.
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	var all []T
+	for {
+		page, ok, err := p.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return all, nil
+		}
+		all = append(all, page.Data...)
+	}
+}
@@ -0,0 +1,104 @@
+package sms
+
+import "unicode/utf16"
+
+// Encoding is the character set a message is sent in.
+type Encoding string
+
+const (
+	GSM7 Encoding = "GSM-7"
+	UCS2 Encoding = "UCS-2"
+)
+
+// Segment sizes: a message that fits in one segment gets the full size; a
+// longer one is split into parts that each lose room to the concatenation
+// header.
+const (
+	gsm7Single = 160
+	gsm7Part   = 153
+	ucs2Single = 70
+	ucs2Part   = 67
+)
+
+// gsm7Basic is the GSM 03.38 default alphabet; each character takes one
+// septet.
+var gsm7Basic = map[rune]bool{}
+
+// gsm7Extended characters are sent as an escape plus a septet, so they count
+// twice and are never split across segments.
+var gsm7Extended = map[rune]bool{
+	'\f': true, '^': true, '{': true, '}': true, '\\': true,
+	'[': true, '~': true, ']': true, '|': true, '€': true,
+}
+
+func init() {
+	for _, r := range "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+		"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà" {
+		gsm7Basic[r] = true
+	}
+}
+
+// SegmentInfo describes how a text is split for sending.
+type SegmentInfo struct {
+	Encoding Encoding `json:"encoding"`
+	// Characters is the number of characters as the user sees them.
+	Characters int `json:"characters"`
+	// Units is the length in the encoding: septets for GSM-7 (extended
+	// characters count two) and UTF-16 code units for UCS-2 (most emoji
+	// count two).
+	Units int `json:"units"`
+	// Segments is the number of SMS parts billed for one recipient.
+	Segments int `json:"segments"`
+	// PerSegment is the capacity of each segment in Units.
+	PerSegment int `json:"per_segment"`
+}
+
+// Segments works out the encoding and number of parts of text. Any character
+// outside the GSM-7 alphabet, such as Arabic, makes the whole message UCS-2.
+func Segments(text string) SegmentInfo {
+	runes := []rune(text)
+	info := SegmentInfo{Encoding: GSM7, Characters: len(runes)}
+	for _, r := range runes {
+		if !gsm7Basic[r] && !gsm7Extended[r] {
+			info.Encoding = UCS2
+			break
+		}
+	}
+
+	// widths holds the units of each character, which must not be split
+	// across two segments.
+	widths := make([]int, len(runes))
+	for i, r := range runes {
+		switch {
+		case info.Encoding == GSM7 && gsm7Extended[r]:
+			widths[i] = 2
+		case info.Encoding == UCS2:
+			widths[i] = len(utf16.Encode([]rune{r}))
+		default:
+			widths[i] = 1
+		}
+		info.Units += widths[i]
+	}
+
+	single, part := gsm7Single, gsm7Part
+	if info.Encoding == UCS2 {
+		single, part = ucs2Single, ucs2Part
+	}
+	if info.Units <= single {
+		info.PerSegment = single
+		info.Segments = 1
+		return info
+	}
+
+	info.PerSegment = part
+	info.Segments = 1
+	used := 0
+	for _, w := range widths {
+		if used+w > part {
+			info.Segments++
+			used = 0
+		}
+		used += w
+	}
+	return info
+}
@@ -0,0 +1,58 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+
+	"fourjawaly-cli/fourjawaly"
+)
+
+// MaxNumbersPerRequest is the largest recipient list sent in one request;
+// bigger lists are split into chunks by the caller.
+const MaxNumbersPerRequest = 100
+
+// Message is one text sent from sender to numbers.
+type Message struct {
+	Text    string   `json:"text"`
+	Numbers []string `json:"numbers"`
+	Sender  string   `json:"sender"`
+	// SendAt schedules the message for later, as "2006-01-02 15:04:05" in
+	// Timezone (an IANA name such as "Asia/Riyadh"). Empty sends now.
+	SendAt   string `json:"send_at,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// SendRequest is the body of POST /account/area/sms/send.
+type SendRequest struct {
+	Messages []Message `json:"messages"`
+}
+
+// NewSendRequest returns a request carrying a single message.
+func NewSendRequest(text, sender string, numbers []string) SendRequest {
+	return SendRequest{
+		Messages: []Message{{Text: text, Numbers: numbers, Sender: sender}},
+	}
+}
+
+// SendURL is the endpoint Send posts to.
+func (c *Client) SendURL() string {
+	return c.url("/account/area/sms/send", nil)
+}
+
+// Send submits req. A non-2xx response yields a *fourjawaly.APIError. When the
+// API accepts the request but reports an err_text for a message, both the
+// result and a *fourjawaly.APIError carrying that text are returned.
+func (c *Client) Send(ctx context.Context, req SendRequest) (*SendResult, error) {
+	res, err := c.transport.Do(ctx, http.MethodPost, c.SendURL(), req)
+	if err != nil {
+		return nil, err
+	}
+	var out SendResult
+	if err := fourjawaly.Decode(res, &out); err != nil {
+		return nil, err
+	}
+	if text := out.ErrText(); text != "" {
+		return &out, &fourjawaly.APIError{StatusCode: res.StatusCode, Message: text, Body: res.Body}
+	}
+	return &out, nil
+}
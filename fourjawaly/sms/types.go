@@ -0,0 +1,169 @@
+package sms
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"fourjawaly-cli/fourjawaly"
+)
+
+// SendResult is the decoded response of Send.
+type SendResult struct {
+	fourjawaly.Meta
+	JobID    fourjawaly.FlexString `json:"job_id"`
+	Message  string                `json:"message"`
+	Messages []MessageResult       `json:"messages"`
+}
+
+// MessageResult reports what happened to one entry of SendRequest.Messages.
+type MessageResult struct {
+	InsertedNumbers fourjawaly.FlexInt `json:"inserted_numbers"`
+	ErrText         string             `json:"err_text,omitempty"`
+}
+
+// ErrText returns the first err_text reported for any message, or "".
+func (r *SendResult) ErrText() string {
+	for _, m := range r.Messages {
+		if m.ErrText != "" {
+			return m.ErrText
+		}
+	}
+	return ""
+}
+
+// Collection is one page of a return_collection=1 listing.
+type Collection[T any] struct {
+	CurrentPage fourjawaly.FlexInt `json:"current_page"`
+	LastPage    fourjawaly.FlexInt `json:"last_page"`
+	PerPage     fourjawaly.FlexInt `json:"per_page"`
+	Total       fourjawaly.FlexInt `json:"total"`
+	Data        []T                `json:"data"`
+}
+
+// UnmarshalJSON also accepts a bare array of items.
+func (c *Collection[T]) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		*c = Collection[T]{}
+		return json.Unmarshal(trimmed, &c.Data)
+	}
+	type plain Collection[T]
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// collectionBody finds the collection under either of the keys the API uses.
+type collectionBody[T any] struct {
+	Items      *Collection[T] `json:"items"`
+	Collection *Collection[T] `json:"collection"`
+}
+
+func (b collectionBody[T]) page() Collection[T] {
+	switch {
+	case b.Items != nil:
+		return *b.Items
+	case b.Collection != nil:
+		return *b.Collection
+	}
+	return Collection[T]{}
+}
+
+// Package is one balance package on the account.
+type Package struct {
+	ID            fourjawaly.FlexInt   `json:"id"`
+	PackagePoints fourjawaly.FlexFloat `json:"package_points"`
+	CurrentPoints fourjawaly.FlexFloat `json:"current_points"`
+	ExpireAt      string               `json:"expire_at"`
+	IsActive      fourjawaly.FlexInt   `json:"is_active"`
+}
+
+// PackagesResult is the decoded response of Packages.
+type PackagesResult struct {
+	fourjawaly.Meta
+	TotalBalance fourjawaly.FlexFloat `json:"total_balance"`
+	Packages     Collection[Package]  `json:"-"`
+}
+
+// Sender is one sender name on the account.
+type Sender struct {
+	ID         fourjawaly.FlexInt `json:"id"`
+	SenderName string             `json:"sender_name"`
+	Status     fourjawaly.FlexInt `json:"status"`
+	IsDefault  fourjawaly.FlexInt `json:"is_default"`
+	Note       string             `json:"note"`
+}
+
+// Sender statuses. Only approved names can be used to send.
+const (
+	SenderPending  = 0
+	SenderApproved = 1
+	SenderRejected = 2
+)
+
+// State names the sender status.
+func (s Sender) State() string {
+	switch s.Status {
+	case SenderPending:
+		return "pending"
+	case SenderApproved:
+		return "approved"
+	case SenderRejected:
+		return "rejected"
+	}
+	return "unknown"
+}
+
+// Approved reports whether the name can be used to send.
+func (s Sender) Approved() bool {
+	return s.Status == SenderApproved
+}
+
+// SendersResult is the decoded response of Senders.
+type SendersResult struct {
+	fourjawaly.Meta
+	Senders Collection[Sender] `json:"-"`
+}
+
+// SenderRequestResult is the decoded response of RequestSender.
+type SenderRequestResult struct {
+	fourjawaly.Meta
+	Message string  `json:"message"`
+	Sender  *Sender `json:"-"`
+}
+
+func (r *PackagesResult) UnmarshalJSON(data []byte) error {
+	var body struct {
+		TotalBalance fourjawaly.FlexFloat `json:"total_balance"`
+		collectionBody[Package]
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+	r.TotalBalance = body.TotalBalance
+	r.Packages = body.page()
+	return nil
+}
+
+func (r *SendersResult) UnmarshalJSON(data []byte) error {
+	var body collectionBody[Sender]
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+	r.Senders = body.page()
+	return nil
+}
+
+func (r *SenderRequestResult) UnmarshalJSON(data []byte) error {
+	var body struct {
+		Message string  `json:"message"`
+		Item    *Sender `json:"item"`
+		Data    *Sender `json:"data"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+	r.Message = body.Message
+	r.Sender = body.Item
+	if r.Sender == nil {
+		r.Sender = body.Data
+	}
+	return nil
+}
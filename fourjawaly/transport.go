@@ -0,0 +1,137 @@
+// Package fourjawaly holds the pieces shared by the sms and whatsapp clients:
+// credentials, the default HTTP client and the authenticated JSON transport.
+package fourjawaly
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout bounds every request made through DefaultHTTPClient.
+const DefaultTimeout = 30 * time.Second
+
+// DefaultHTTPClient is used by clients that were not given their own *http.Client.
+var DefaultHTTPClient = &http.Client{
+	Timeout: DefaultTimeout,
+}
+
+// Credentials are the app key / API secret pair issued by 4Jawaly.
+type Credentials struct {
+	AppKey    string
+	APISecret string
+}
+
+// BasicAuth returns the value of the Authorization header for c.
+func (c Credentials) BasicAuth() string {
+	token := base64.StdEncoding.EncodeToString([]byte(c.AppKey + ":" + c.APISecret))
+	return "Basic " + token
+}
+
+// Response is a raw API response.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Decode unmarshals the response body into v.
+func (r *Response) Decode(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Transport performs authenticated JSON requests against the 4Jawaly APIs.
+// The zero Retry makes a single attempt.
+type Transport struct {
+	HTTPClient  *http.Client
+	Credentials Credentials
+	Retry       RetryPolicy
+}
+
+// Do sends payload (if non-nil) as JSON to endpoint and returns the response,
+// retrying transient failures according to t.Retry. A non-2xx status is not
+// an error at this level.
+func (t *Transport) Do(ctx context.Context, method, endpoint string, payload any) (*Response, error) {
+	var data []byte
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := t.do(ctx, method, endpoint, data)
+		if attempt >= t.Retry.MaxAttempts || !t.Retry.retryable(ctx, method, res, err) {
+			return res, err
+		}
+
+		delay := t.Retry.backoff(attempt)
+		reason := err
+		if res != nil {
+			if d, ok := retryAfter(res.Header); ok {
+				if t.Retry.MaxRetryAfter > 0 && d > t.Retry.MaxRetryAfter {
+					return res, nil
+				}
+				delay = d
+			}
+			reason = NewAPIError(res)
+		}
+		if t.Retry.OnRetry != nil {
+			t.Retry.OnRetry(attempt+1, delay, reason)
+		}
+		if err := sleepCtx(ctx, delay); err != nil {
+			if res != nil {
+				return res, nil
+			}
+			return nil, &NetworkError{Err: err}
+		}
+	}
+}
+
+func (t *Transport) do(ctx context.Context, method, endpoint string, data []byte) (*Response, error) {
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+
+	// WroteRequest runs on the transport's own goroutine.
+	var written atomic.Bool
+	trace := &httptrace.ClientTrace{
+		WroteRequest: func(info httptrace.WroteRequestInfo) { written.Store(info.Err == nil) },
+	}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", t.Credentials.BasicAuth())
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	client := t.HTTPClient
+	if client == nil {
+		client = DefaultHTTPClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &NetworkError{Err: err, Written: written.Load()}
+	}
+	defer resp.Body.Close()
+
+	resBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &NetworkError{Err: err, Written: true}
+	}
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       resBody,
+	}, nil
+}
@@ -0,0 +1,138 @@
+// Package whatsapp is a client for the 4Jawaly WhatsApp API.
+package whatsapp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"fourjawaly-cli/fourjawaly"
+)
+
+// DefaultBaseURL is the production WhatsApp API.
+const DefaultBaseURL = "https://api-users.4jawaly.com/api/v1/whatsapp"
+
+// Paths understood by the project endpoint.
+const (
+	PathGlobal   = "global"
+	PathLocation = "message/location"
+	PathContact  = "message/contact"
+)
+
+// Client talks to one WhatsApp project.
+type Client struct {
+	baseURL   string
+	projectID string
+	transport fourjawaly.Transport
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL overrides DefaultBaseURL.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		if u := strings.TrimRight(strings.TrimSpace(baseURL), "/"); u != "" {
+			c.baseURL = u
+		}
+	}
+}
+
+// WithHTTPClient overrides fourjawaly.DefaultHTTPClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.transport.HTTPClient = hc
+	}
+}
+
+// WithRetry overrides fourjawaly.DefaultRetryPolicy; pass the zero
+// RetryPolicy to disable retries.
+func WithRetry(p fourjawaly.RetryPolicy) Option {
+	return func(c *Client) {
+		c.transport.Retry = p
+	}
+}
+
+// NewClient returns a Client for projectID authenticated with appKey and apiSecret.
+func NewClient(appKey, apiSecret, projectID string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:   DefaultBaseURL,
+		projectID: projectID,
+		transport: fourjawaly.Transport{
+			Credentials: fourjawaly.Credentials{AppKey: appKey, APISecret: apiSecret},
+			Retry:       fourjawaly.DefaultRetryPolicy,
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Endpoint is the project URL every request is posted to.
+func (c *Client) Endpoint() string {
+	return c.baseURL + "/" + c.projectID
+}
+
+// Request is the envelope posted to the project endpoint.
+type Request struct {
+	Path   string `json:"path"`
+	Params any    `json:"params"`
+}
+
+// Result is the decoded response of a project request.
+type Result struct {
+	fourjawaly.Meta
+	Messages []SentMessage `json:"messages"`
+	Error    *struct {
+		Code    fourjawaly.FlexInt `json:"code"`
+		Message string             `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// SentMessage identifies a message accepted by WhatsApp.
+type SentMessage struct {
+	ID string `json:"id"`
+}
+
+// Do posts req to the project endpoint. A non-2xx response, or a 2xx response
+// carrying an "error" object, yields a *fourjawaly.APIError.
+func (c *Client) Do(ctx context.Context, req Request) (*Result, error) {
+	res, err := c.transport.Do(ctx, http.MethodPost, c.Endpoint(), req)
+	if err != nil {
+		return nil, err
+	}
+	var out Result
+	if err := fourjawaly.Decode(res, &out); err != nil {
+		return nil, err
+	}
+	if out.Error != nil {
+		return &out, fourjawaly.NewAPIError(res)
+	}
+	return &out, nil
+}
+
+// Send delivers msg to the given recipient.
+func (c *Client) Send(ctx context.Context, to string, msg Message) (*Result, error) {
+	return c.Do(ctx, NewMessageRequest(to, msg))
+}
+
+// BusinessProfile reads the project's WhatsApp business profile. It sends
+// nothing, which makes it a cheap check that the credentials and project ID
+// are usable.
+func (c *Client) BusinessProfile(ctx context.Context) (*Result, error) {
+	return c.Do(ctx, Request{
+		Path:   PathGlobal,
+		Params: globalParams{URL: "whatsapp_business_profile", Method: "get"},
+	})
+}
+
+// SendLocation delivers a location pin.
+func (c *Client) SendLocation(ctx context.Context, loc Location) (*Result, error) {
+	return c.Do(ctx, Request{Path: PathLocation, Params: loc})
+}
+
+// SendContact delivers one or more contact cards to phone.
+func (c *Client) SendContact(ctx context.Context, phone string, cards ...ContactCard) (*Result, error) {
+	return c.Do(ctx, NewContactRequest(phone, cards...))
+}
@@ -0,0 +1,237 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"fourjawaly-cli/fourjawaly"
+)
+
+// captured is what the test server saw of the last request.
+type captured struct {
+	method, path, auth, contentType string
+	body                            any
+}
+
+// testServer answers every request with status and body, recording it.
+func testServer(t *testing.T, status int, body string) (*httptest.Server, *captured) {
+	t.Helper()
+	got := &captured{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		got.method, got.path = r.Method, r.URL.Path
+		got.auth, got.contentType = r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		if err := json.Unmarshal(data, &got.body); err != nil {
+			t.Errorf("request body %q: %v", data, err)
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, got
+}
+
+func jsonValue(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("bad test JSON %q: %v", s, err)
+	}
+	return v
+}
+
+// global wraps data the way the CLI always posted session messages.
+func global(data string) string {
+	return `{"path":"global","params":{"url":"messages","method":"post","data":` + data + `}}`
+}
+
+// TestClientPayloads checks each request against the JSON the CLI sent
+// before the client existed.
+func TestClientPayloads(t *testing.T) {
+	tests := []struct {
+		name string
+		send func(context.Context, *Client) error
+		want string
+	}{
+		{
+			name: "text",
+			send: func(ctx context.Context, c *Client) error {
+				_, err := c.Send(ctx, "966501234567", TextMessage("مرحبا"))
+				return err
+			},
+			want: global(`{"messaging_product":"whatsapp","to":"966501234567","type":"text","text":{"body":"مرحبا"}}`),
+		},
+		{
+			name: "buttons",
+			send: func(ctx context.Context, c *Client) error {
+				_, err := c.Send(ctx, "966501234567", ButtonsMessage("اختر", []Button{{ID: "y", Title: "نعم"}, {ID: "n", Title: "لا"}}))
+				return err
+			},
+			want: global(`{"messaging_product":"whatsapp","to":"966501234567","type":"interactive","interactive":{"type":"button",` +
+				`"body":{"text":"اختر"},"action":{"buttons":[{"type":"reply","reply":{"id":"y","title":"نعم"}},{"type":"reply","reply":{"id":"n","title":"لا"}}]}}}`),
+		},
+		{
+			name: "list without a footer",
+			send: func(ctx context.Context, c *Client) error {
+				_, err := c.Send(ctx, "966501234567", ListMessage(List{
+					Header: "القائمة", Body: "اختر", Button: "عرض",
+					Sections: []Section{{Title: "قسم", Rows: []Row{{ID: "1", Title: "أ", Description: "وصف"}}}},
+				}))
+				return err
+			},
+			want: global(`{"messaging_product":"whatsapp","to":"966501234567","type":"interactive","interactive":{"type":"list",` +
+				`"header":{"type":"text","text":"القائمة"},"body":{"text":"اختر"},"footer":{"text":""},` +
+				`"action":{"button":"عرض","sections":[{"title":"قسم","rows":[{"id":"1","title":"أ","description":"وصف"}]}]}}}`),
+		},
+		{
+			name: "image without a caption",
+			send: func(ctx context.Context, c *Client) error {
+				_, err := c.Send(ctx, "966501234567", ImageMessage("https://example.com/a.png", ""))
+				return err
+			},
+			want: global(`{"messaging_product":"whatsapp","to":"966501234567","type":"image","image":{"link":"https://example.com/a.png"}}`),
+		},
+		{
+			name: "video",
+			send: func(ctx context.Context, c *Client) error {
+				_, err := c.Send(ctx, "966501234567", VideoMessage("https://example.com/a.mp4", "فيديو"))
+				return err
+			},
+			want: global(`{"messaging_product":"whatsapp","to":"966501234567","type":"video","video":{"link":"https://example.com/a.mp4","caption":"فيديو"}}`),
+		},
+		{
+			name: "audio",
+			send: func(ctx context.Context, c *Client) error {
+				_, err := c.Send(ctx, "966501234567", AudioMessage("https://example.com/a.mp3"))
+				return err
+			},
+			want: global(`{"messaging_product":"whatsapp","to":"966501234567","type":"audio","audio":{"link":"https://example.com/a.mp3"}}`),
+		},
+		{
+			name: "document",
+			send: func(ctx context.Context, c *Client) error {
+				_, err := c.Send(ctx, "966501234567", DocumentMessage("https://example.com/a.pdf", "", "a.pdf"))
+				return err
+			},
+			want: global(`{"messaging_product":"whatsapp","to":"966501234567","type":"document","document":{"link":"https://example.com/a.pdf","filename":"a.pdf"}}`),
+		},
+		{
+			name: "location",
+			send: func(ctx context.Context, c *Client) error {
+				_, err := c.SendLocation(ctx, Location{Phone: "966501234567", Lat: 24.7136, Lng: 46.6753, Name: "الرياض"})
+				return err
+			},
+			want: `{"path":"message/location","params":{"phone":"966501234567","lat":24.7136,"lng":46.6753,"address":"","name":"الرياض"}}`,
+		},
+		{
+			name: "contact",
+			send: func(ctx context.Context, c *Client) error {
+				_, err := c.SendContact(ctx, "966501234567", NewContactCard("سارة أحمد علي", "966507654321"), NewContactCard("علي", "966500000000"))
+				return err
+			},
+			want: `{"path":"message/contact","params":{"phone":"966501234567","contacts":[` +
+				`{"name":{"formatted_name":"سارة أحمد علي","first_name":"سارة","last_name":"أحمد علي"},"phones":[{"phone":"966507654321","type":"CELL"}]},` +
+				`{"name":{"formatted_name":"علي","first_name":"علي","last_name":""},"phones":[{"phone":"966500000000","type":"CELL"}]}]}}`,
+		},
+		{
+			name: "business profile",
+			send: func(ctx context.Context, c *Client) error {
+				_, err := c.BusinessProfile(ctx)
+				return err
+			},
+			want: `{"path":"global","params":{"url":"whatsapp_business_profile","method":"get"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, got := testServer(t, http.StatusOK, `{"messages":[{"id":"wamid.1"}]}`)
+			c := NewClient("key", "secret", "proj", WithBaseURL(srv.URL+"/"))
+			if err := tt.send(context.Background(), c); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+			if got.method != http.MethodPost || got.path != "/proj" {
+				t.Errorf("request = %s %s, want POST /proj", got.method, got.path)
+			}
+			if want := "Basic " + base64.StdEncoding.EncodeToString([]byte("key:secret")); got.auth != want {
+				t.Errorf("Authorization = %q, want %q", got.auth, want)
+			}
+			if got.contentType != "application/json" {
+				t.Errorf("Content-Type = %q", got.contentType)
+			}
+			if want := jsonValue(t, tt.want); !reflect.DeepEqual(got.body, want) {
+				t.Errorf("body =\n%v\nwant\n%v", got.body, want)
+			}
+		})
+	}
+}
+
+func TestClientResponses(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		ids          []string
+		code         int
+		message      string
+		rejected     bool
+		unauthorized bool
+	}{
+		{name: "accepted", status: http.StatusOK, body: `{"messages":[{"id":"wamid.1"}]}`, ids: []string{"wamid.1"}},
+		{
+			name:     "error object in a 200",
+			status:   http.StatusOK,
+			body:     `{"error":{"code":131047,"message":"Re-engagement message"}}`,
+			code:     131047,
+			message:  "Re-engagement message",
+			rejected: true,
+		},
+		{
+			name:         "refused credentials",
+			status:       http.StatusUnauthorized,
+			body:         `{"code":401,"message":"Unauthenticated."}`,
+			code:         401,
+			message:      "Unauthenticated.",
+			unauthorized: true,
+		},
+		{name: "plain text error", status: http.StatusNotFound, body: "project not found", message: "project not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := testServer(t, tt.status, tt.body)
+			c := NewClient("key", "secret", "proj", WithBaseURL(srv.URL))
+			res, err := c.Send(context.Background(), "966501234567", TextMessage("hi"))
+			if tt.message == "" {
+				if err != nil {
+					t.Fatalf("Send: %v", err)
+				}
+				var ids []string
+				for _, m := range res.Messages {
+					ids = append(ids, m.ID)
+				}
+				if !reflect.DeepEqual(ids, tt.ids) || res.StatusCode != tt.status {
+					t.Errorf("Send = %v (HTTP %d), want %v", ids, res.StatusCode, tt.ids)
+				}
+				return
+			}
+			var apiErr *fourjawaly.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Send error = %#v, want a *fourjawaly.APIError", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Code != tt.code || apiErr.Message != tt.message {
+				t.Errorf("APIError = HTTP %d, code %d, %q, want HTTP %d, code %d, %q",
+					apiErr.StatusCode, apiErr.Code, apiErr.Message, tt.status, tt.code, tt.message)
+			}
+			if apiErr.Rejected() != tt.rejected || apiErr.Unauthorized() != tt.unauthorized {
+				t.Errorf("Rejected, Unauthorized = %v, %v, want %v, %v",
+					apiErr.Rejected(), apiErr.Unauthorized(), tt.rejected, tt.unauthorized)
+			}
+		})
+	}
+}
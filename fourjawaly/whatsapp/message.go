@@ -0,0 +1,335 @@
+package whatsapp
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Limits enforced by WhatsApp on interactive messages.
+const (
+	MaxButtons  = 3
+	MaxListRows = 10
+)
+
+// Message is a session or template message; exactly one of the content fields
+// matches Type.
+type Message struct {
+	Type        string       `json:"type"`
+	Text        *Text        `json:"text,omitempty"`
+	Interactive *Interactive `json:"interactive,omitempty"`
+	Image       *Media       `json:"image,omitempty"`
+	Video       *Media       `json:"video,omitempty"`
+	Audio       *Media       `json:"audio,omitempty"`
+	Document    *Media       `json:"document,omitempty"`
+	Template    *Template    `json:"template,omitempty"`
+}
+
+type Text struct {
+	Body string `json:"body"`
+}
+
+type Media struct {
+	Link     string `json:"link"`
+	Caption  string `json:"caption,omitempty"`
+	Filename string `json:"filename,omitempty"`
+}
+
+type Interactive struct {
+	Type   string             `json:"type"`
+	Header *InteractiveHeader `json:"header,omitempty"`
+	Body   InteractiveText    `json:"body"`
+	Footer *InteractiveText   `json:"footer,omitempty"`
+	Action InteractiveAction  `json:"action"`
+}
+
+type InteractiveHeader struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type InteractiveText struct {
+	Text string `json:"text"`
+}
+
+type InteractiveAction struct {
+	Button   string        `json:"button,omitempty"`
+	Buttons  []ReplyButton `json:"buttons,omitempty"`
+	Sections []Section     `json:"sections,omitempty"`
+}
+
+type ReplyButton struct {
+	Type  string `json:"type"`
+	Reply Button `json:"reply"`
+}
+
+// Button is a quick-reply button shown under an interactive message.
+type Button struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type Section struct {
+	Title string `json:"title"`
+	Rows  []Row  `json:"rows"`
+}
+
+type Row struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// List describes an interactive list message.
+type List struct {
+	Header   string
+	Body     string
+	Footer   string
+	Button   string
+	Sections []Section
+}
+
+// MaxTemplateButtons is the most buttons a template may have; button
+// components are addressed by their 0-based index.
+const MaxTemplateButtons = 10
+
+// Template is an approved message template (HSM), the only kind of message
+// that may open a conversation outside the 24-hour window.
+type Template struct {
+	Name       string
+	Language   TemplateLanguage
+	Components []TemplateComponent
+	// RawComponents, if set, is sent as the components array in place of
+	// Components, untouched. It covers parameter types and fields the typed
+	// components do not model, such as currency or date_time.
+	RawComponents json.RawMessage
+}
+
+func (t Template) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Name       string           `json:"name"`
+		Language   TemplateLanguage `json:"language"`
+		Components any              `json:"components,omitempty"`
+	}{Name: t.Name, Language: t.Language}
+	switch {
+	case len(t.RawComponents) > 0:
+		out.Components = t.RawComponents
+	case len(t.Components) > 0:
+		out.Components = t.Components
+	}
+	return json.Marshal(out)
+}
+
+type TemplateLanguage struct {
+	Code string `json:"code"`
+}
+
+// TemplateComponent fills the placeholders of one part of a template:
+// "header", "body" or a "button" picked by Index.
+type TemplateComponent struct {
+	Type string `json:"type"`
+	// SubType and Index apply to buttons only: "quick_reply" or "url".
+	SubType    string              `json:"sub_type,omitempty"`
+	Index      string              `json:"index,omitempty"`
+	Parameters []TemplateParameter `json:"parameters"`
+}
+
+// TemplateParameter is one placeholder value; exactly one of the value fields
+// matches Type.
+type TemplateParameter struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Payload  string `json:"payload,omitempty"`
+	Image    *Media `json:"image,omitempty"`
+	Video    *Media `json:"video,omitempty"`
+	Document *Media `json:"document,omitempty"`
+}
+
+// TextParameter returns a text placeholder value.
+func TextParameter(text string) TemplateParameter {
+	return TemplateParameter{Type: "text", Text: text}
+}
+
+// BodyComponent fills the body placeholders {{1}}, {{2}}... in order.
+func BodyComponent(values ...string) TemplateComponent {
+	params := make([]TemplateParameter, 0, len(values))
+	for _, v := range values {
+		params = append(params, TextParameter(v))
+	}
+	return TemplateComponent{Type: "body", Parameters: params}
+}
+
+// HeaderTextComponent fills the {{1}} of a text header.
+func HeaderTextComponent(text string) TemplateComponent {
+	return TemplateComponent{Type: "header", Parameters: []TemplateParameter{TextParameter(text)}}
+}
+
+// HeaderMediaComponent sets the media of an image, video or document header;
+// filename is used for documents only and may be empty.
+func HeaderMediaComponent(kind, link, filename string) TemplateComponent {
+	p := TemplateParameter{Type: kind}
+	switch kind {
+	case "image":
+		p.Image = &Media{Link: link}
+	case "video":
+		p.Video = &Media{Link: link}
+	case "document":
+		p.Document = &Media{Link: link, Filename: filename}
+	}
+	return TemplateComponent{Type: "header", Parameters: []TemplateParameter{p}}
+}
+
+// QuickReplyButtonComponent sets the payload returned when the quick-reply
+// button at index is tapped.
+func QuickReplyButtonComponent(index int, payload string) TemplateComponent {
+	return TemplateComponent{
+		Type:       "button",
+		SubType:    "quick_reply",
+		Index:      strconv.Itoa(index),
+		Parameters: []TemplateParameter{{Type: "payload", Payload: payload}},
+	}
+}
+
+// URLButtonComponent sets the suffix appended to the dynamic URL of the
+// button at index.
+func URLButtonComponent(index int, suffix string) TemplateComponent {
+	return TemplateComponent{
+		Type:       "button",
+		SubType:    "url",
+		Index:      strconv.Itoa(index),
+		Parameters: []TemplateParameter{TextParameter(suffix)},
+	}
+}
+
+// TemplateMessage returns a template message.
+func TemplateMessage(t Template) Message {
+	return Message{Type: "template", Template: &t}
+}
+
+// TextMessage returns a plain text message.
+func TextMessage(body string) Message {
+	return Message{Type: "text", Text: &Text{Body: body}}
+}
+
+// ButtonsMessage returns an interactive message with reply buttons.
+func ButtonsMessage(body string, buttons []Button) Message {
+	replies := make([]ReplyButton, 0, len(buttons))
+	for _, b := range buttons {
+		replies = append(replies, ReplyButton{Type: "reply", Reply: b})
+	}
+	return Message{
+		Type: "interactive",
+		Interactive: &Interactive{
+			Type:   "button",
+			Body:   InteractiveText{Text: body},
+			Action: InteractiveAction{Buttons: replies},
+		},
+	}
+}
+
+// ListMessage returns an interactive list message.
+func ListMessage(l List) Message {
+	return Message{
+		Type: "interactive",
+		Interactive: &Interactive{
+			Type:   "list",
+			Header: &InteractiveHeader{Type: "text", Text: l.Header},
+			Body:   InteractiveText{Text: l.Body},
+			Footer: &InteractiveText{Text: l.Footer},
+			Action: InteractiveAction{Button: l.Button, Sections: l.Sections},
+		},
+	}
+}
+
+// ImageMessage returns an image message; caption may be empty.
+func ImageMessage(link, caption string) Message {
+	return Message{Type: "image", Image: &Media{Link: link, Caption: caption}}
+}
+
+// VideoMessage returns a video message; caption may be empty.
+func VideoMessage(link, caption string) Message {
+	return Message{Type: "video", Video: &Media{Link: link, Caption: caption}}
+}
+
+// AudioMessage returns an audio message.
+func AudioMessage(link string) Message {
+	return Message{Type: "audio", Audio: &Media{Link: link}}
+}
+
+// DocumentMessage returns a document message; caption and filename may be empty.
+func DocumentMessage(link, caption, filename string) Message {
+	return Message{Type: "document", Document: &Media{Link: link, Caption: caption, Filename: filename}}
+}
+
+type outboundMessage struct {
+	MessagingProduct string `json:"messaging_product"`
+	To               string `json:"to"`
+	Message
+}
+
+type globalParams struct {
+	URL    string `json:"url"`
+	Method string `json:"method"`
+	Data   any    `json:"data,omitempty"`
+}
+
+// NewMessageRequest wraps msg in the "global" envelope the project endpoint expects.
+func NewMessageRequest(to string, msg Message) Request {
+	return Request{
+		Path: PathGlobal,
+		Params: globalParams{
+			URL:    "messages",
+			Method: "post",
+			Data:   outboundMessage{MessagingProduct: "whatsapp", To: to, Message: msg},
+		},
+	}
+}
+
+// Location is the params of a message/location request.
+type Location struct {
+	Phone   string  `json:"phone"`
+	Lat     float64 `json:"lat"`
+	Lng     float64 `json:"lng"`
+	Address string  `json:"address"`
+	Name    string  `json:"name"`
+}
+
+type ContactCard struct {
+	Name   ContactName    `json:"name"`
+	Phones []ContactPhone `json:"phones"`
+}
+
+type ContactName struct {
+	FormattedName string `json:"formatted_name"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+}
+
+type ContactPhone struct {
+	Phone string `json:"phone"`
+	Type  string `json:"type"`
+}
+
+type contactParams struct {
+	Phone    string        `json:"phone"`
+	Contacts []ContactCard `json:"contacts"`
+}
+
+// NewContactRequest returns a message/contact request sending cards to phone.
+func NewContactRequest(phone string, cards ...ContactCard) Request {
+	return Request{Path: PathContact, Params: contactParams{Phone: phone, Contacts: cards}}
+}
+
+// NewContactCard builds a card for fullName, splitting it at the first space
+// into first and last name.
+func NewContactCard(fullName, phone string) ContactCard {
+	parts := strings.SplitN(fullName, " ", 2)
+	card := ContactCard{
+		Name:   ContactName{FormattedName: fullName, FirstName: parts[0]},
+		Phones: []ContactPhone{{Phone: phone, Type: "CELL"}},
+	}
+	if len(parts) > 1 {
+		card.Name.LastName = parts[1]
+	}
+	return card
+}
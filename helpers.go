@@ -1,20 +1,58 @@
 package main
 
 import (
-	"encoding/base64"
+	"bufio"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
+
+	"fourjawaly-cli/fourjawaly"
+	"fourjawaly-cli/fourjawaly/phone"
 )
 
 const Version = "1.1.0"
 
 var httpClient = &http.Client{
-	Timeout: 30 * time.Second,
+	Timeout: fourjawaly.DefaultTimeout,
+}
+
+var retryFlags = struct {
+	Retries    int
+	Delay      time.Duration
+	MaxDelay   time.Duration
+	RetrySends bool
+}{
+	Retries:  fourjawaly.DefaultRetryPolicy.MaxAttempts - 1,
+	Delay:    fourjawaly.DefaultRetryPolicy.BaseDelay,
+	MaxDelay: fourjawaly.DefaultRetryPolicy.MaxDelay,
+}
+
+func addRetryFlags(fs *flag.FlagSet) {
+	fs.IntVar(&retryFlags.Retries, "retries", retryFlags.Retries, "عدد مرات إعادة المحاولة عند أخطاء الشبكة و 429 و 5xx (0 للتعطيل)")
+	fs.DurationVar(&retryFlags.Delay, "retry-delay", retryFlags.Delay, "الانتظار قبل أول إعادة محاولة، ويتضاعف بعدها")
+	fs.DurationVar(&retryFlags.MaxDelay, "retry-max-delay", retryFlags.MaxDelay, "أقصى انتظار بين المحاولات")
+	fs.BoolVar(&retryFlags.RetrySends, "retry-sends", retryFlags.RetrySends, "إعادة طلبات الإرسال أيضًا بعد 5xx أو انقطاع بعد وصول الطلب (قد تصل الرسالة مرتين)")
+}
+
+func retryPolicy() fourjawaly.RetryPolicy {
+	p := fourjawaly.DefaultRetryPolicy
+	p.MaxAttempts = max(retryFlags.Retries, 0) + 1
+	p.BaseDelay = retryFlags.Delay
+	p.MaxDelay = retryFlags.MaxDelay
+	p.RetryUnsafe = retryFlags.RetrySends
+	p.OnRetry = func(attempt int, delay time.Duration, reason error) {
+		progressf("محاولة %d بعد %s: %v\n", attempt, delay.Round(time.Millisecond), reason)
+	}
+	return p
 }
 
 func envOrDefault(key, fallback string) string {
@@ -33,26 +71,71 @@ func firstNonEmpty(values ...string) string {
 	return ""
 }
 
-func resolveAppKey(flag string) string {
-	return firstNonEmpty(flag, envOrDefault("FOURJAWALY_APP_KEY", ""), envOrDefault("APP_KEY", ""))
+// resolveAppKey and resolveAPISecret look in flags, then the environment, then
+// what auth login stored for the profile, then the config file. The *_FILE
+// variables come right after their plain counterparts.
+func resolveAppKey(flag string, p *profile) (string, error) {
+	if v := firstNonEmpty(flag, envOrDefault("FOURJAWALY_APP_KEY", "")); v != "" {
+		return v, nil
+	}
+	if path := envOrDefault("FOURJAWALY_APP_KEY_FILE", ""); path != "" {
+		return readSecretFile(path, "FOURJAWALY_APP_KEY_FILE")
+	}
+	if v := envOrDefault("APP_KEY", ""); v != "" {
+		return v, nil
+	}
+	stored, err := storedCredentials(p.Name)
+	if err != nil || stored == nil {
+		return p.AppKey, err
+	}
+	return firstNonEmpty(stored.AppKey, p.AppKey), nil
+}
+
+func resolveAPISecret(flag string, p *profile) (string, error) {
+	flag = strings.TrimSpace(flag)
+	if flag != "" && apiSecretFile != "" {
+		return "", usageErrorf("--api-secret و --api-secret-file لا يجتمعان")
+	}
+	switch {
+	case flag == "-":
+		return readSecretStdin()
+	case flag != "":
+		warnPlainSecret()
+		return flag, nil
+	case apiSecretFile != "":
+		return readSecretFile(apiSecretFile, "--api-secret-file")
+	}
+	if v := envOrDefault("FOURJAWALY_API_SECRET", ""); v != "" {
+		return v, nil
+	}
+	if path := envOrDefault("FOURJAWALY_API_SECRET_FILE", ""); path != "" {
+		return readSecretFile(path, "FOURJAWALY_API_SECRET_FILE")
+	}
+	if v := envOrDefault("API_SECRET", ""); v != "" {
+		return v, nil
+	}
+	stored, err := storedCredentials(p.Name)
+	if err != nil || stored == nil {
+		return p.APISecret, err
+	}
+	return firstNonEmpty(stored.APISecret, p.APISecret), nil
 }
 
-func resolveAPISecret(flag string) string {
-	return firstNonEmpty(flag, envOrDefault("FOURJAWALY_API_SECRET", ""), envOrDefault("API_SECRET", ""))
+func resolveCountry(flag string) (string, error) {
+	country := strings.ToUpper(firstNonEmpty(flag, envOrDefault("FOURJAWALY_DEFAULT_COUNTRY", ""), phone.DefaultCountry))
+	if _, ok := phone.Lookup(country); !ok {
+		return "", usageErrorf("دولة غير مدعومة %q (مثال: SA, AE, KW, EG)", country)
+	}
+	return country, nil
 }
 
 func requireAuth(appKey, apiSecret string) error {
 	if appKey == "" || apiSecret == "" {
-		return fmt.Errorf("مطلوب app-key و api-secret (عبر flags أو متغيرات البيئة FOURJAWALY_APP_KEY / FOURJAWALY_API_SECRET)")
+		return &authError{msg: "مطلوب app-key و api-secret (عبر flags أو متغيرات البيئة FOURJAWALY_APP_KEY / FOURJAWALY_API_SECRET أو auth login أو --profile من ملف الإعدادات)"}
 	}
 	return nil
 }
 
-func basicAuthHeader(appKey, apiSecret string) string {
-	token := base64.StdEncoding.EncodeToString([]byte(appKey + ":" + apiSecret))
-	return "Basic " + token
-}
-
 func prettyPrintJSON(v any) error {
 	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
@@ -74,43 +157,89 @@ func splitAndCleanCSV(input string) []string {
 	return result
 }
 
-func doRequest(req *http.Request) ([]byte, int, error) {
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return nil, 0, err
+func requireNonEmpty(value, flagName string) error {
+	if strings.TrimSpace(value) == "" {
+		return usageErrorf("مطلوب %s", flagName)
 	}
-	defer resp.Body.Close()
+	return nil
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, resp.StatusCode, err
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// stdin is shared by every prompt, so that piped answers are not lost to a
+// reader's buffer.
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question on stderr and reads the answer from stdin;
+// anything but y/yes/نعم is a no.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", question)
+	answer, _ := stdin.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes", "نعم":
+		return true
 	}
-	return body, resp.StatusCode, nil
+	return false
 }
 
-func printResponse(resBody []byte, status int) error {
-	var out any
-	if err := json.Unmarshal(resBody, &out); err != nil {
-		fmt.Printf("HTTP %d\n%s\n", status, string(resBody))
-		return nil
+// promptLine asks for one line on stderr and reads it from stdin. With hidden
+// set, terminal echo is turned off while it is typed.
+func promptLine(prompt string, hidden bool) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+	if hidden && isTerminal(os.Stdin) {
+		if err := stty("-echo"); err == nil {
+			interrupted := make(chan os.Signal, 1)
+			signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+			done := make(chan struct{})
+			defer func() {
+				close(done)
+				signal.Stop(interrupted)
+				_ = stty("echo")
+				fmt.Fprintln(os.Stderr)
+			}()
+			go func() {
+				select {
+				case <-interrupted:
+					_ = stty("echo")
+					fmt.Fprintln(os.Stderr)
+					os.Exit(exitInterrupted)
+				case <-done:
+				}
+			}()
+		}
+	}
+	line, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", validationErrorf("تعذرت قراءة %s", prompt)
 	}
-	fmt.Printf("HTTP %d\n", status)
-	return prettyPrintJSON(out)
+	return strings.TrimSpace(line), nil
 }
 
-func dryRunPrint(method, endpoint string, payload any) error {
-	fmt.Println("[dry-run] لن يتم الإرسال الفعلي")
-	fmt.Printf("[dry-run] %s %s\n", method, endpoint)
-	return prettyPrintJSON(payload)
+func stty(args ...string) error {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
 }
 
-func requireNonEmpty(value, flagName string) error {
-	if strings.TrimSpace(value) == "" {
-		return fmt.Errorf("مطلوب %s", flagName)
+// nonNil makes an empty listing encode as [] rather than null.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
 	}
-	return nil
+	return items
 }
 
 func trimFlag(v *string) string {
 	return strings.TrimSpace(*v)
 }
+
+func formatInt(n fourjawaly.FlexInt) string {
+	return strconv.FormatInt(int64(n), 10)
+}
+
+func formatFloat(n fourjawaly.FlexFloat) string {
+	return strconv.FormatFloat(float64(n), 'f', -1, 64)
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fourjawaly-cli/fourjawaly/phone"
+	"fourjawaly-cli/fourjawaly/sms"
+)
+
+// Defaults for sms history: pages as large as the API allows and a cap so an
+// unfiltered query does not download the whole account by accident. The API
+// cannot filter by status, so --status also bounds the pages it scans.
+const (
+	historyPageSize        = 100
+	defaultHistoryLimit    = 1000
+	defaultHistoryMaxPages = 20
+)
+
+type historyRow struct {
+	ID          string            `json:"id"`
+	JobID       string            `json:"job_id"`
+	Number      string            `json:"number"`
+	Sender      string            `json:"sender"`
+	State       sms.DeliveryState `json:"state"`
+	Status      string            `json:"status,omitempty"`
+	SentAt      string            `json:"sent_at,omitempty"`
+	DeliveredAt string            `json:"delivered_at,omitempty"`
+	Text        string            `json:"text"`
+}
+
+var historyHeader = []string{"id", "job_id", "number", "sender", "state", "status", "sent_at", "delivered_at", "text"}
+
+func (r historyRow) record() []string {
+	return []string{r.ID, r.JobID, r.Number, r.Sender, string(r.State), r.Status, r.SentAt, r.DeliveredAt, r.Text}
+}
+
+func runSMSHistory(args []string) error {
+	fs := flag.NewFlagSet("sms history", flag.ContinueOnError)
+	appKeyFlag := fs.String("app-key", "", "مفتاح API")
+	apiSecretFlag := fs.String("api-secret", "", "سر API (أو - للقراءة من stdin)")
+	addSecretFileFlag(fs)
+	sinceFlag := fs.String("since", "", "من تاريخ YYYY-MM-DD")
+	untilFlag := fs.String("until", "", "إلى تاريخ YYYY-MM-DD (شامل)")
+	senderFlag := fs.String("sender", "", "اسم المرسل")
+	numberFlag := fs.String("number", "", "رقم المستلم")
+	countryFlag := fs.String("country", "", "الدولة الافتراضية لـ --number المحلي (الافتراضي: SA)")
+	statusFlag := fs.String("status", "", "الحالة: pending|sent|delivered|failed|unknown")
+	limit := fs.Int("limit", defaultHistoryLimit, "أقصى عدد رسائل (0 بلا حد)")
+	maxPages := fs.Int("max-pages", defaultHistoryMaxPages, "أقصى عدد صفحات يُبحث فيها مع --status (0 بلا حد)")
+	exportFlag := fs.String("export", "", "حفظ النتائج في ملف csv أو json حسب الامتداد")
+	baseURLFlag := fs.String("base-url", "", "رابط API (الافتراضي: "+defaultSMSBaseURL+")")
+	addGlobalFlags(fs)
+	addRetryFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *limit < 0 {
+		return usageErrorf("--limit لا يقبل قيمة سالبة")
+	}
+	if *maxPages < 0 {
+		return usageErrorf("--max-pages لا يقبل قيمة سالبة")
+	}
+
+	q := sms.MessageQuery{Sender: trimFlag(senderFlag), PageSize: historyPageSize}
+	var err error
+	if q.From, err = parseDateFlag("--since", trimFlag(sinceFlag)); err != nil {
+		return err
+	}
+	if q.To, err = parseDateFlag("--until", trimFlag(untilFlag)); err != nil {
+		return err
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return usageErrorf("--until قبل --since")
+	}
+	var country string
+	if number := trimFlag(numberFlag); number != "" {
+		if country, err = resolveCountry(trimFlag(countryFlag)); err != nil {
+			return err
+		}
+		if q.Number, err = phone.Normalize(number, country); err != nil {
+			return validationErrorf("--number: %v", err)
+		}
+	}
+	state := sms.DeliveryState(strings.ToLower(trimFlag(statusFlag)))
+	switch state {
+	case "", sms.StatePending, sms.StateSent, sms.StateDelivered, sms.StateFailed, sms.StateUnknown:
+	default:
+		return usageErrorf("حالة غير معروفة %q (المتاح: pending, sent, delivered, failed, unknown)", *statusFlag)
+	}
+
+	cfg, err := resolveSMSConfig(*appKeyFlag, *apiSecretFlag, *baseURLFlag)
+	if err != nil {
+		return err
+	}
+	client := cfg.client()
+
+	// The status filter is applied here because the API's own status codes
+	// are not the normalized states; sender and number are checked again in
+	// case the endpoint ignores those filters.
+	ctx := context.Background()
+	pager := sms.NewPager(func(ctx context.Context, page int) (sms.Collection[sms.SentMessage], error) {
+		q.Page = page
+		res, err := client.Messages(ctx, q)
+		if err != nil {
+			return sms.Collection[sms.SentMessage]{}, err
+		}
+		return res.Messages, nil
+	})
+	var rows []historyRow
+	truncated, exhausted := false, false
+	for pages := 0; !truncated; pages++ {
+		// Only --status filters here, so a rare one could otherwise walk
+		// the whole account.
+		if state != "" && *maxPages > 0 && pages == *maxPages {
+			exhausted = true
+			break
+		}
+		c, ok, err := pager.Next(ctx)
+		if err != nil {
+			return reportAPIError(err)
+		}
+		if !ok {
+			break
+		}
+		for _, m := range c.Data {
+			if state != "" && m.State() != state ||
+				q.Sender != "" && !strings.EqualFold(m.SenderName, q.Sender) ||
+				q.Number != "" && historyNumber(m.Number.String(), country) != q.Number {
+				continue
+			}
+			if *limit > 0 && len(rows) == *limit {
+				truncated = true
+				break
+			}
+			rows = append(rows, historyRow{
+				ID:          m.ID.String(),
+				JobID:       m.JobID.String(),
+				Number:      m.Number.String(),
+				Sender:      m.SenderName,
+				State:       m.State(),
+				Status:      firstNonEmpty(m.StatusText, m.Status.String()),
+				SentAt:      m.CreatedAt,
+				DeliveredAt: m.DeliveredAt,
+				Text:        m.Text,
+			})
+		}
+		if int(c.CurrentPage) < int(c.LastPage) {
+			progressf("الصفحة %d من %d...\n", c.CurrentPage, c.LastPage)
+		}
+	}
+	if truncated {
+		progressf("تم الاكتفاء بأول %d رسالة (استخدم --limit 0 للكل)\n", *limit)
+	}
+	if exhausted {
+		progressf("تحذير: توقف البحث عن الحالة %s بعد %d صفحة ووُجدت %d؛ قد توجد نتائج أقدم (ضيّق --since/--until أو استخدم --max-pages 0)\n",
+			state, *maxPages, len(rows))
+	}
+
+	if path := trimFlag(exportFlag); path != "" {
+		if err := exportHistory(path, rows); err != nil {
+			return err
+		}
+		progressf("تم حفظ %d رسالة في %s\n", len(rows), path)
+	}
+
+	t := &table{header: historyHeader}
+	for _, r := range rows {
+		t.rows = append(t.rows, r.record())
+	}
+	return emitValue(nonNil(rows), t)
+}
+
+// historyNumber normalizes a number as the API returned it (with +, 00 or in
+// local form) so it compares with --number; one that does not parse is kept
+// as is.
+func historyNumber(raw, country string) string {
+	if n, err := phone.Normalize(raw, country); err == nil {
+		return n
+	}
+	return raw
+}
+
+// parseDateFlag accepts YYYY-MM-DD; an empty value is the zero time.
+func parseDateFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, usageErrorf("%s: تاريخ غير صحيح %q (الصيغة YYYY-MM-DD)", name, value)
+	}
+	return t, nil
+}
+
+func exportHistory(path string, rows []historyRow) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return validationErrorf("تعذر إنشاء ملف التصدير: %v", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(nonNil(rows)); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+
+	w := csv.NewWriter(f)
+	_ = w.Write(historyHeader)
+	for _, r := range rows {
+		_ = w.Write(r.record())
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
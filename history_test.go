@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHistoryNumber(t *testing.T) {
+	for raw, want := range map[string]string{
+		"966501234567":     "966501234567",
+		"+966501234567":    "966501234567",
+		"00966501234567":   "966501234567",
+		"0501234567":       "966501234567",
+		"+966 50 123 4567": "966501234567",
+		"not-a-number":     "not-a-number",
+		"":                 "",
+	} {
+		if got := historyNumber(raw, "SA"); got != want {
+			t.Errorf("historyNumber(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestSMSHistoryNumberFilter(t *testing.T) {
+	setupCommandEnv(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"code": 200,
+			"items": map[string]any{"current_page": 1, "last_page": 1, "data": []map[string]any{
+				{"id": 1, "number": "+966501234567", "status": "delivered"},
+				{"id": 2, "number": "00966501234567", "status": "delivered"},
+				{"id": 3, "number": "0501234567", "status": "delivered"},
+				{"id": 4, "number": "966507654321", "status": "delivered"},
+				{"id": 5, "number": "garbage", "status": "delivered"},
+			}},
+		})
+	}))
+	defer srv.Close()
+
+	export := filepath.Join(t.TempDir(), "out.csv")
+	err := runSMSHistory([]string{"--number", "0501234567", "--base-url", srv.URL, "--export", export, "--output", "quiet"})
+	if err != nil {
+		t.Fatalf("runSMSHistory: %v", err)
+	}
+	f, err := os.Open(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, r := range records[1:] {
+		ids = append(ids, r[0])
+	}
+	if want := []string{"1", "2", "3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("exported ids = %v, want %v", ids, want)
+	}
+}
+
+func TestExportHistory(t *testing.T) {
+	rows := []historyRow{{ID: "1", Number: "966501234567", State: "delivered", Text: "مرحبا، \"عالم\""}}
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "out.JSON")
+	if err := exportHistory(jsonPath, rows); err != nil {
+		t.Fatalf("exportHistory json: %v", err)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []historyRow
+	if err := json.Unmarshal(data, &got); err != nil || !reflect.DeepEqual(got, rows) {
+		t.Errorf("json export = %s (%v)", data, err)
+	}
+
+	csvPath := filepath.Join(dir, "out.csv")
+	if err := exportHistory(csvPath, rows); err != nil {
+		t.Fatalf("exportHistory csv: %v", err)
+	}
+	f, err := os.Open(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{historyHeader, rows[0].record()}; !reflect.DeepEqual(records, want) {
+		t.Errorf("csv export = %q, want %q", records, want)
+	}
+
+	if err := exportHistory(filepath.Join(dir, "missing", "out.csv"), rows); err == nil {
+		t.Error("exportHistory into a missing directory succeeded")
+	}
+}
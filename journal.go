@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fourjawaly-cli/fourjawaly"
+	"fourjawaly-cli/fourjawaly/sms"
+)
+
+// A journal is an append-only JSONL file describing a bulk send: one
+// "campaign" line, one "chunk" line per planned request, then a "started"
+// line before each request and a "result" line after it. Every line is
+// synced to disk, so after a crash the file tells exactly which chunks got an
+// answer. Credentials are never written.
+
+const journalVersion = 1
+
+// Journal statuses recorded in "result" lines.
+const (
+	journalSent    = "sent"
+	journalFailed  = "failed"
+	journalUnknown = "unknown"
+)
+
+type journalEntry struct {
+	Type       string        `json:"type"`
+	Time       time.Time     `json:"time"`
+	Version    int           `json:"version,omitempty"`
+	BaseURL    string        `json:"base_url,omitempty"`
+	Chunks     int           `json:"chunks,omitempty"`
+	Numbers    int           `json:"numbers,omitempty"`
+	Index      int           `json:"index"`
+	Messages   []sms.Message `json:"messages,omitempty"`
+	Status     string        `json:"status,omitempty"`
+	HTTPStatus int           `json:"http_status,omitempty"`
+	JobID      string        `json:"job_id,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+type journalWriter struct {
+	mu   sync.Mutex
+	f    *os.File
+	path string
+}
+
+// defaultJournalPath places journals under $XDG_STATE_HOME (or ~/.local/state).
+func defaultJournalPath() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	name := "sms-" + time.Now().Format("20060102-150405") + ".jsonl"
+	return filepath.Join(dir, "4jawaly-cli", "journals", name), nil
+}
+
+// createJournal starts a new journal at path (or the default location) and
+// records the plan of the campaign.
+func createJournal(path string, cfg smsConfig, chunks []smsChunk, numbers int) (*journalWriter, error) {
+	if path == "" {
+		var err error
+		if path, err = defaultJournalPath(); err != nil {
+			return nil, fmt.Errorf("تعذر تحديد مكان ملف التتبع: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return nil, validationErrorf("ملف التتبع %s موجود مسبقًا، استخدم: 4jawaly-cli sms resume %s", path, path)
+		}
+		return nil, err
+	}
+
+	j := &journalWriter{f: f, path: path}
+	if err := j.write(journalEntry{Type: "campaign", Version: journalVersion, BaseURL: cfg.BaseURL, Chunks: len(chunks), Numbers: numbers}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	for _, c := range chunks {
+		if err := j.write(journalEntry{Type: "chunk", Index: c.Index, Messages: c.Messages}); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// appendJournal reopens an existing journal to record a resumed run.
+func appendJournal(path string) (*journalWriter, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &journalWriter{f: f, path: path}, nil
+}
+
+func (j *journalWriter) Path() string {
+	return j.path
+}
+
+func (j *journalWriter) Close() error {
+	if j == nil {
+		return nil
+	}
+	return j.f.Close()
+}
+
+func (j *journalWriter) write(e journalEntry) error {
+	e.Time = time.Now().UTC()
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return j.f.Sync()
+}
+
+// Started and Result are no-ops on a nil writer (--no-journal). A failed
+// write is reported but does not stop the campaign.
+func (j *journalWriter) Started(index int) {
+	if j == nil {
+		return
+	}
+	if err := j.write(journalEntry{Type: "started", Index: index}); err != nil {
+		fmt.Fprintf(os.Stderr, "تحذير: تعذر الكتابة في ملف التتبع: %v\n", err)
+	}
+}
+
+func (j *journalWriter) Result(cr chunkResult) {
+	if j == nil {
+		return
+	}
+	e := journalEntry{Type: "result", Index: cr.Index, Status: journalFailed, HTTPStatus: cr.StatusCode}
+	var apiErr *fourjawaly.APIError
+	if errors.As(cr.Error, &apiErr) {
+		e.HTTPStatus = apiErr.StatusCode
+	}
+	if cr.Result != nil {
+		e.JobID = cr.Result.JobID.String()
+	}
+	switch outcome := classifyChunk(cr); {
+	case outcome == outcomeSuccess:
+		e.Status = journalSent
+	case outcome == outcomeUnknown:
+		e.Status = journalUnknown
+		e.Error = "رد بدون messages"
+	}
+	if cr.Error != nil {
+		e.Error = cr.Error.Error()
+	}
+	if err := j.write(e); err != nil {
+		fmt.Fprintf(os.Stderr, "تحذير: تعذر الكتابة في ملف التتبع: %v\n", err)
+	}
+}
+
+// journalState is a journal replayed into the latest state of every chunk.
+type journalState struct {
+	Campaign journalEntry
+	Chunks   []smsChunk
+	Sent     map[int]journalEntry // chunks with a successful result
+	// Uncertain chunks were started but have no result: the request may or
+	// may not have reached the API before the process stopped.
+	Uncertain map[int]bool
+}
+
+func readJournal(path string) (*journalState, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, validationErrorf("تعذر فتح ملف التتبع: %v", err)
+	}
+	defer f.Close()
+
+	st := &journalState{Sent: map[int]journalEntry{}, Uncertain: map[int]bool{}}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e journalEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			// A crash can leave the last line half-written; anything
+			// before it is still trustworthy.
+			fmt.Fprintf(os.Stderr, "تحذير: تجاهل السطر %d التالف في ملف التتبع\n", line)
+			continue
+		}
+		switch e.Type {
+		case "campaign":
+			if e.Version > journalVersion {
+				return nil, validationErrorf("ملف التتبع بإصدار أحدث (%d) من هذه النسخة", e.Version)
+			}
+			st.Campaign = e
+		case "chunk":
+			st.Chunks = append(st.Chunks, smsChunk{Index: e.Index, Messages: e.Messages})
+		case "started":
+			if _, done := st.Sent[e.Index]; !done {
+				st.Uncertain[e.Index] = true
+			}
+		case "result":
+			delete(st.Uncertain, e.Index)
+			switch e.Status {
+			case journalSent:
+				st.Sent[e.Index] = e
+			case journalUnknown:
+				st.Uncertain[e.Index] = true
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if st.Campaign.Type == "" || len(st.Chunks) == 0 {
+		return nil, validationErrorf("%s ليس ملف تتبع إرسال مجمّع", path)
+	}
+	return st, nil
+}
@@ -1,70 +1,106 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		printRootUsage()
-		os.Exit(1)
+	args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "خطأ: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if len(args) < 1 {
+		printRootUsage(os.Stderr)
+		os.Exit(exitUsage)
 	}
 
-	var err error
-	switch os.Args[1] {
+	switch args[0] {
 	case "sms":
-		err = runSMS(os.Args[2:])
+		err = runSMS(args[1:])
 	case "wa":
-		err = runWhatsApp(os.Args[2:])
+		err = runWhatsApp(args[1:])
+	case "auth":
+		err = runAuth(args[1:])
+	case "template":
+		err = runTemplate(args[1:])
 	case "version", "-v", "--version":
 		fmt.Printf("4jawaly-cli v%s\n", Version)
 		return
 	case "help", "-h", "--help":
-		printRootUsage()
+		printRootUsage(os.Stdout)
 		return
 	default:
-		err = fmt.Errorf("أمر غير معروف %q", os.Args[1])
+		err = usageErrorf("أمر غير معروف %q", args[0])
 	}
 
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "خطأ: %v\n\n", err)
-		printRootUsage()
-		os.Exit(1)
+	if code := exitCode(err); code != exitOK {
+		fmt.Fprintf(os.Stderr, "خطأ: %v\n", err)
+		var usage *usageError
+		if errors.As(err, &usage) {
+			fmt.Fprintln(os.Stderr)
+			printRootUsage(os.Stderr)
+		}
+		os.Exit(code)
 	}
 }
 
-func printRootUsage() {
-	fmt.Printf("4Jawaly CLI v%s (إرسال فقط)\n", Version)
-	fmt.Println("")
-	fmt.Println("الاستخدام:")
-	fmt.Println("  4jawaly-cli sms <أمر> [خيارات]")
-	fmt.Println("  4jawaly-cli wa  <أمر> [خيارات]")
-	fmt.Println("")
-	fmt.Println("أوامر SMS:")
-	fmt.Println("  send        إرسال رسالة نصية")
-	fmt.Println("  balance     عرض الرصيد")
-	fmt.Println("  senders     عرض أسماء المرسلين")
-	fmt.Println("")
-	fmt.Println("أوامر WhatsApp:")
-	fmt.Println("  send-text       إرسال رسالة نصية")
-	fmt.Println("  send-buttons    إرسال أزرار تفاعلية")
-	fmt.Println("  send-list       إرسال قائمة تفاعلية")
-	fmt.Println("  send-image      إرسال صورة")
-	fmt.Println("  send-video      إرسال فيديو")
-	fmt.Println("  send-audio      إرسال ملف صوتي")
-	fmt.Println("  send-document   إرسال مستند")
-	fmt.Println("  send-location   إرسال موقع جغرافي")
-	fmt.Println("  send-contact    إرسال جهة اتصال")
-	fmt.Println("")
-	fmt.Println("أوامر عامة:")
-	fmt.Println("  version     عرض رقم الإصدار")
-	fmt.Println("  help        عرض المساعدة")
-	fmt.Println("")
-	fmt.Println("خيارات مشتركة:")
-	fmt.Println("  --app-key       مفتاح API")
-	fmt.Println("  --api-secret    سر API")
-	fmt.Println("  --dry-run       معاينة بدون إرسال فعلي")
-	fmt.Println("")
-	fmt.Println("استخدم --help مع أي أمر فرعي للتفاصيل.")
+func printRootUsage(w io.Writer) {
+	fmt.Fprintf(w, "4Jawaly CLI v%s (إرسال فقط)\n", Version)
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "الاستخدام:")
+	fmt.Fprintln(w, "  4jawaly-cli [--output <صيغة>] [--profile <اسم>] sms <أمر> [خيارات]")
+	fmt.Fprintln(w, "  4jawaly-cli [--output <صيغة>] [--profile <اسم>] wa  <أمر> [خيارات]")
+	fmt.Fprintln(w, "  4jawaly-cli [--profile <اسم>] auth <أمر>")
+	fmt.Fprintln(w, "  4jawaly-cli [--output <صيغة>] template <أمر>")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "أوامر SMS:")
+	fmt.Fprintln(w, "  send        إرسال رسالة نصية")
+	fmt.Fprintln(w, "  balance     عرض الرصيد")
+	fmt.Fprintln(w, "  senders     عرض أسماء المرسلين")
+	fmt.Fprintln(w, "  resume      استئناف إرسال مجمّع من ملف التتبع")
+	fmt.Fprintln(w, "  status      حالة توصيل الرسائل حسب job_id")
+	fmt.Fprintln(w, "  history     سجل الرسائل المرسلة مع البحث والتصدير")
+	fmt.Fprintln(w, "  estimate    حساب عدد الأجزاء والتكلفة قبل الإرسال")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "أوامر WhatsApp:")
+	fmt.Fprintln(w, "  send-text       إرسال رسالة نصية")
+	fmt.Fprintln(w, "  send-buttons    إرسال أزرار تفاعلية")
+	fmt.Fprintln(w, "  send-list       إرسال قائمة تفاعلية")
+	fmt.Fprintln(w, "  send-image      إرسال صورة")
+	fmt.Fprintln(w, "  send-video      إرسال فيديو")
+	fmt.Fprintln(w, "  send-audio      إرسال ملف صوتي")
+	fmt.Fprintln(w, "  send-document   إرسال مستند")
+	fmt.Fprintln(w, "  send-location   إرسال موقع جغرافي")
+	fmt.Fprintln(w, "  send-contact    إرسال جهة اتصال")
+	fmt.Fprintln(w, "  send-template   إرسال قالب معتمد (HSM) لبدء محادثة خارج نافذة 24 ساعة")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "أوامر auth:")
+	fmt.Fprintln(w, "  login       حفظ بيانات الدخول في keyring أو ملف مشفر")
+	fmt.Fprintln(w, "  logout      حذف بيانات الدخول المحفوظة")
+	fmt.Fprintln(w, "  status      مصدر بيانات الدخول الحالية")
+	fmt.Fprintln(w, "  verify      التحقق من بيانات الدخول ورقم مشروع واتساب")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "أوامر template:")
+	fmt.Fprintln(w, "  add         حفظ نص رسالة باسم مع متغيرات مثل {{.code}}")
+	fmt.Fprintln(w, "  list        عرض القوالب المحفوظة")
+	fmt.Fprintln(w, "  show        عرض نص قالب ومتغيراته")
+	fmt.Fprintln(w, "  rm          حذف قالب")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "أوامر عامة:")
+	fmt.Fprintln(w, "  version     عرض رقم الإصدار")
+	fmt.Fprintln(w, "  help        عرض المساعدة")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "خيارات مشتركة:")
+	fmt.Fprintln(w, "  --app-key       مفتاح API")
+	fmt.Fprintln(w, "  --api-secret    سر API (- للقراءة من stdin)")
+	fmt.Fprintln(w, "  --api-secret-file  ملف يحتوي سر API")
+	fmt.Fprintln(w, "  --dry-run       معاينة بدون إرسال فعلي")
+	fmt.Fprintln(w, "  --output        صيغة المخرجات: text|json|table|csv|quiet (أو FOURJAWALY_OUTPUT)")
+	fmt.Fprintln(w, "  --profile       ملف التعريف من ~/.config/4jawaly/config.yaml (أو FOURJAWALY_PROFILE)")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "استخدم --help مع أي أمر فرعي للتفاصيل.")
 }
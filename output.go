@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"fourjawaly-cli/fourjawaly"
+)
+
+type outputMode string
+
+const (
+	outputText  outputMode = "text"
+	outputJSON  outputMode = "json"
+	outputTable outputMode = "table"
+	outputCSV   outputMode = "csv"
+	outputQuiet outputMode = "quiet"
+)
+
+// output is set by --output (before or after the command name) or by
+// FOURJAWALY_OUTPUT; the default keeps the original human-readable format.
+var output = outputText
+
+func (m *outputMode) String() string {
+	return string(*m)
+}
+
+func (m *outputMode) Set(v string) error {
+	switch mode := outputMode(strings.ToLower(strings.TrimSpace(v))); mode {
+	case outputText, outputJSON, outputTable, outputCSV, outputQuiet:
+		*m = mode
+		return nil
+	}
+	return fmt.Errorf("صيغة مخرجات غير معروفة %q (المتاح: text, json, table, csv, quiet)", v)
+}
+
+// addGlobalFlags registers the flags every command accepts after its name as
+// well as before it.
+func addGlobalFlags(fs *flag.FlagSet) {
+	fs.Var(&output, "output", "صيغة المخرجات: text|json|table|csv|quiet")
+	fs.StringVar(&profileName, "profile", profileName, "ملف التعريف من ملف الإعدادات (أو FOURJAWALY_PROFILE)")
+}
+
+// parseGlobalFlags applies FOURJAWALY_OUTPUT and consumes any --output and
+// --profile flags given before the command name, returning the remaining
+// arguments.
+func parseGlobalFlags(args []string) ([]string, error) {
+	if env := envOrDefault("FOURJAWALY_OUTPUT", ""); env != "" {
+		if err := output.Set(env); err != nil {
+			return nil, &usageError{msg: "FOURJAWALY_OUTPUT: " + err.Error()}
+		}
+	}
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+		if name != "output" && name != "profile" {
+			break
+		}
+		if !hasValue {
+			if len(args) < 2 {
+				return nil, usageErrorf("مطلوب قيمة لـ --%s", name)
+			}
+			value = args[1]
+			args = args[1:]
+		}
+		args = args[1:]
+		if name == "profile" {
+			profileName = strings.TrimSpace(value)
+			continue
+		}
+		if err := output.Set(value); err != nil {
+			return nil, &usageError{msg: err.Error()}
+		}
+	}
+	return args, nil
+}
+
+// table is the tabular form of a result, used by --output table and csv.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) write() error {
+	if output == outputCSV {
+		w := csv.NewWriter(os.Stdout)
+		_ = w.Write(t.header)
+		_ = w.WriteAll(t.rows)
+		return w.Error()
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// emitResult prints the response behind a decoded result. t may be nil for
+// results without a tabular form, in which case table and csv print JSON.
+func emitResult(m fourjawaly.Meta, t *table) error {
+	switch output {
+	case outputQuiet:
+		return nil
+	case outputText:
+		return printResponse(m.Raw, m.StatusCode)
+	case outputTable, outputCSV:
+		if t != nil {
+			return t.write()
+		}
+	}
+	return printRawJSON(m.Raw)
+}
+
+// emitValue prints a value built by the CLI itself, such as a bulk summary.
+func emitValue(v any, t *table) error {
+	switch output {
+	case outputQuiet:
+		return nil
+	case outputTable, outputCSV:
+		if t != nil {
+			return t.write()
+		}
+	}
+	return prettyPrintJSON(v)
+}
+
+// progressf reports progress on stderr so that stdout stays parseable.
+func progressf(format string, args ...any) {
+	if output == outputQuiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+func printRawJSON(raw []byte) error {
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		fmt.Println(string(raw))
+		return nil
+	}
+	return prettyPrintJSON(out)
+}
+
+func printResponse(resBody []byte, status int) error {
+	var out any
+	if err := json.Unmarshal(resBody, &out); err != nil {
+		fmt.Printf("HTTP %d\n%s\n", status, string(resBody))
+		return nil
+	}
+	fmt.Printf("HTTP %d\n", status)
+	return prettyPrintJSON(out)
+}
+
+// reportAPIError prints the body of an API error response, if err carries
+// one, and returns err unchanged.
+func reportAPIError(err error) error {
+	var apiErr *fourjawaly.APIError
+	if !errors.As(err, &apiErr) || len(apiErr.Body) == 0 {
+		return err
+	}
+	switch output {
+	case outputText:
+		_ = printResponse(apiErr.Body, apiErr.StatusCode)
+	case outputJSON:
+		_ = printRawJSON(apiErr.Body)
+	}
+	return err
+}
+
+func dryRunPrint(method, endpoint string, payload any) error {
+	switch output {
+	case outputQuiet:
+		return nil
+	case outputText:
+		fmt.Println("[dry-run] لن يتم الإرسال الفعلي")
+		fmt.Printf("[dry-run] %s %s\n", method, endpoint)
+		return prettyPrintJSON(payload)
+	}
+	return prettyPrintJSON(map[string]any{
+		"dry_run":  true,
+		"method":   method,
+		"endpoint": endpoint,
+		"payload":  payload,
+	})
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+
+	"fourjawaly-cli/fourjawaly/sms"
+)
+
+// pageFlags are the paging flags of the listing commands.
+type pageFlags struct {
+	All      bool
+	Page     int
+	PageSize int
+}
+
+func addPageFlags(fs *flag.FlagSet, defaultPageSize int) *pageFlags {
+	p := &pageFlags{}
+	fs.BoolVar(&p.All, "all", false, "جلب كل الصفحات")
+	fs.IntVar(&p.Page, "page", 1, "رقم الصفحة")
+	fs.IntVar(&p.PageSize, "page-size", defaultPageSize, "عدد العناصر في الصفحة")
+	return p
+}
+
+func (p *pageFlags) validate(fs *flag.FlagSet) error {
+	if p.Page < 1 {
+		return usageErrorf("--page يجب أن يكون 1 أو أكثر")
+	}
+	if p.PageSize < 1 {
+		return usageErrorf("--page-size يجب أن يكون 1 أو أكثر")
+	}
+	if p.All && flagSet(fs, "page") {
+		return usageErrorf("--all و --page لا يجتمعان")
+	}
+	return nil
+}
+
+// warnMorePages tells the user, on stderr, that a single page is not the
+// whole listing.
+func warnMorePages[T any](c sms.Collection[T]) {
+	if int(c.CurrentPage) < int(c.LastPage) {
+		progressf("الصفحة %d من %d (الإجمالي %d)، استخدم --all لعرض الكل أو --page للتنقل\n", c.CurrentPage, c.LastPage, c.Total)
+	}
+}
+
+// parseIntFilter reads a numeric filter flag where "all" (or empty) means no
+// filter.
+func parseIntFilter(name, value string) (*int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "all") {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, usageErrorf("%s: قيمة غير صحيحة %q (رقم أو all)", name, value)
+	}
+	return &n, nil
+}
+
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
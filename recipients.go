@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fourjawaly-cli/fourjawaly/phone"
+	"fourjawaly-cli/fourjawaly/sms"
+)
+
+// recipient is one number to send to, with the other columns of its row
+// available to the message template.
+type recipient struct {
+	Phone  string
+	Fields map[string]string
+	Source string // "--to" or "file:line", used in error messages
+}
+
+// phoneColumnNames are tried, in order, when --phone-column is not given.
+var phoneColumnNames = []string{"phone", "number", "mobile", "to", "الجوال", "الرقم"}
+
+// recipientFlags select the numbers of an SMS and how they are cleaned up.
+type recipientFlags struct {
+	To              string
+	ToFile          string
+	PhoneColumn     string
+	Country         string
+	SkipInvalid     bool
+	AllowDuplicates bool
+}
+
+func addRecipientFlags(fs *flag.FlagSet) *recipientFlags {
+	f := &recipientFlags{}
+	fs.StringVar(&f.To, "to", "", "أرقام مفصولة بفاصلة")
+	fs.StringVar(&f.ToFile, "to-file", "", "ملف الأرقام: txt أو csv أو xlsx")
+	fs.StringVar(&f.PhoneColumn, "phone-column", "", "اسم عمود الرقم في csv/xlsx (الافتراضي: phone)")
+	fs.StringVar(&f.Country, "country", "", "الدولة الافتراضية للأرقام المحلية (الافتراضي: SA)")
+	fs.BoolVar(&f.SkipInvalid, "skip-invalid", false, "تجاهل الأرقام غير الصحيحة بدل إيقاف الإرسال")
+	fs.BoolVar(&f.AllowDuplicates, "allow-duplicates", false, "السماح بتكرار الرقم نفسه (يُحتسب كل تكرار)")
+	return f
+}
+
+// load collects, normalizes and (unless --allow-duplicates) dedupes the
+// recipients, returning how many duplicates were dropped.
+func (f *recipientFlags) load() ([]recipient, int, error) {
+	country, err := resolveCountry(strings.TrimSpace(f.Country))
+	if err != nil {
+		return nil, 0, err
+	}
+	recipients, err := collectRecipients(strings.TrimSpace(f.To), strings.TrimSpace(f.ToFile), strings.TrimSpace(f.PhoneColumn))
+	if err != nil {
+		return nil, 0, err
+	}
+	recipients, err = normalizeRecipients(recipients, country, f.SkipInvalid)
+	if err != nil {
+		return nil, 0, err
+	}
+	duplicates := 0
+	if !f.AllowDuplicates {
+		recipients, duplicates = dedupeRecipients(recipients)
+		if duplicates > 0 {
+			progressf("تم حذف %d رقم مكرر\n", duplicates)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, 0, validationErrorf("لا توجد أرقام في --to أو --to-file")
+	}
+	return recipients, duplicates, nil
+}
+
+// collectRecipients merges the numbers from --to with the rows of --to-file.
+func collectRecipients(to, toFile, phoneColumn string) ([]recipient, error) {
+	var out []recipient
+	for _, n := range splitAndCleanCSV(to) {
+		out = append(out, recipient{Phone: n, Fields: map[string]string{"phone": n}, Source: "--to"})
+	}
+	if toFile == "" {
+		return out, nil
+	}
+
+	var (
+		fromFile []recipient
+		err      error
+	)
+	switch strings.ToLower(filepath.Ext(toFile)) {
+	case ".csv":
+		fromFile, err = readCSVRecipients(toFile, phoneColumn)
+	case ".xlsx":
+		fromFile, err = readXLSXRecipients(toFile, phoneColumn)
+	default:
+		fromFile, err = readTextRecipients(toFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(out, fromFile...), nil
+}
+
+// readTextRecipients reads one or more comma-separated numbers per line.
+// Blank lines and lines starting with # are skipped.
+func readTextRecipients(filename string) ([]recipient, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, validationErrorf("تعذر فتح %s: %v", filename, err)
+	}
+	defer f.Close()
+
+	var out []recipient
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(strings.TrimPrefix(sc.Text(), "\ufeff"))
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		for _, n := range splitAndCleanCSV(text) {
+			out = append(out, recipient{
+				Phone:  n,
+				Fields: map[string]string{"phone": n},
+				Source: fmt.Sprintf("%s:%d", filename, line),
+			})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func readCSVRecipients(filename, phoneColumn string) ([]recipient, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, validationErrorf("تعذر فتح %s: %v", filename, err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var rows [][]string
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, validationErrorf("%s: %v", filename, err)
+		}
+		rows = append(rows, rec)
+	}
+	return tableRecipients(filename, rows, phoneColumn)
+}
+
+func readXLSXRecipients(filename, phoneColumn string) ([]recipient, error) {
+	rows, err := readXLSX(filename)
+	if err != nil {
+		return nil, validationErrorf("%s: %v", filename, err)
+	}
+	return tableRecipients(filename, rows, phoneColumn)
+}
+
+// tableRecipients turns rows whose first row is a header into recipients.
+// Every column is exposed to the template under its header name.
+func tableRecipients(filename string, rows [][]string, phoneColumn string) ([]recipient, error) {
+	if len(rows) == 0 {
+		return nil, validationErrorf("الملف %s فارغ", filename)
+	}
+
+	header := make([]string, len(rows[0]))
+	for i, h := range rows[0] {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+	}
+
+	phoneIdx := -1
+	candidates := phoneColumnNames
+	if phoneColumn != "" {
+		candidates = []string{phoneColumn}
+	}
+	for _, want := range candidates {
+		for i, h := range header {
+			if strings.EqualFold(h, want) {
+				phoneIdx = i
+				break
+			}
+		}
+		if phoneIdx >= 0 {
+			break
+		}
+	}
+	if phoneIdx < 0 {
+		return nil, validationErrorf("لم يتم العثور على عمود الرقم في %s (حدده عبر --phone-column، المتاح: %s)", filename, strings.Join(header, ", "))
+	}
+
+	var out []recipient
+	for i, row := range rows[1:] {
+		if phoneIdx >= len(row) || strings.TrimSpace(row[phoneIdx]) == "" {
+			continue
+		}
+		fields := make(map[string]string, len(header))
+		for j, h := range header {
+			if h == "" {
+				continue
+			}
+			if j < len(row) {
+				fields[h] = strings.TrimSpace(row[j])
+			} else {
+				fields[h] = ""
+			}
+		}
+		phone := strings.TrimSpace(row[phoneIdx])
+		fields["phone"] = phone
+		out = append(out, recipient{
+			Phone:  phone,
+			Fields: fields,
+			Source: fmt.Sprintf("%s:%d", filename, i+2),
+		})
+	}
+	return out, nil
+}
+
+// maxInvalidListed caps how many invalid numbers are spelled out in one error.
+const maxInvalidListed = 10
+
+// normalizeRecipients rewrites every number to international format. Invalid
+// numbers abort the send unless skipInvalid is set, in which case they are
+// reported on stderr and dropped.
+func normalizeRecipients(recipients []recipient, country string, skipInvalid bool) ([]recipient, error) {
+	out := recipients[:0]
+	var invalid []string
+	for _, r := range recipients {
+		n, err := phone.Normalize(r.Phone, country)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s (%s)", err, r.Source))
+			continue
+		}
+		r.Phone = n
+		r.Fields["phone"] = n
+		out = append(out, r)
+	}
+	if len(invalid) == 0 {
+		return out, nil
+	}
+
+	if skipInvalid {
+		for _, line := range invalid {
+			fmt.Fprintf(os.Stderr, "تجاهل: %s\n", line)
+		}
+		progressf("تم تجاهل %d رقم غير صحيح\n", len(invalid))
+		return out, nil
+	}
+
+	listed := invalid
+	if len(listed) > maxInvalidListed {
+		listed = listed[:maxInvalidListed]
+	}
+	msg := fmt.Sprintf("%d رقم غير صحيح (استخدم --skip-invalid لتجاهلها):\n  %s", len(invalid), strings.Join(listed, "\n  "))
+	if len(invalid) > len(listed) {
+		msg += fmt.Sprintf("\n  ... و %d غيرها", len(invalid)-len(listed))
+	}
+	return nil, &validationError{msg: msg}
+}
+
+// dedupeRecipients keeps the first occurrence of every number and returns how
+// many repeats were dropped. It must run after normalizeRecipients so that
+// different spellings of one number count as duplicates.
+func dedupeRecipients(recipients []recipient) ([]recipient, int) {
+	seen := make(map[string]bool, len(recipients))
+	out := recipients[:0]
+	for _, r := range recipients {
+		if seen[r.Phone] {
+			continue
+		}
+		seen[r.Phone] = true
+		out = append(out, r)
+	}
+	return out, len(recipients) - len(out)
+}
+
+// buildSMSMessages renders text for every recipient and groups recipients
+// whose rendered text is identical into one message, in first-seen order.
+// Plain text without template actions is used as-is.
+func buildSMSMessages(text, sender string, recipients []recipient) ([]sms.Message, error) {
+	if !strings.Contains(text, "{{") {
+		numbers := make([]string, 0, len(recipients))
+		for _, r := range recipients {
+			numbers = append(numbers, r.Phone)
+		}
+		return []sms.Message{{Text: text, Numbers: numbers, Sender: sender}}, nil
+	}
+
+	tmpl, err := parseMessageTemplate("message", text)
+	if err != nil {
+		return nil, validationErrorf("قالب --message غير صحيح: %v", err)
+	}
+
+	_, optional := templateFields(tmpl)
+
+	var messages []sms.Message
+	index := make(map[string]int)
+	var sb strings.Builder
+	for _, r := range recipients {
+		sb.Reset()
+		if err := tmpl.Execute(&sb, withOptionalFields(r.Fields, optional)); err != nil {
+			return nil, validationErrorf("تعذر تعبئة القالب للرقم %s (%s): %v", r.Phone, r.Source, err)
+		}
+		rendered := strings.TrimSpace(sb.String())
+		if i, ok := index[rendered]; ok {
+			messages[i].Numbers = append(messages[i].Numbers, r.Phone)
+			continue
+		}
+		index[rendered] = len(messages)
+		messages = append(messages, sms.Message{Text: rendered, Numbers: []string{r.Phone}, Sender: sender})
+	}
+	return messages, nil
+}
+
+// packSMSChunks splits messages into requests carrying at most size numbers
+// in total, keeping each text's numbers together where possible.
+func packSMSChunks(messages []sms.Message, size int) [][]sms.Message {
+	var (
+		chunks [][]sms.Message
+		cur    []sms.Message
+		count  int
+	)
+	for _, m := range messages {
+		for part := m.Numbers; len(part) > 0; {
+			if count == size {
+				chunks = append(chunks, cur)
+				cur, count = nil, 0
+			}
+			n := min(len(part), size-count)
+			piece := m
+			piece.Numbers = part[:n]
+			cur = append(cur, piece)
+			count += n
+			part = part[n:]
+		}
+	}
+	if len(cur) > 0 {
+		chunks = append(chunks, cur)
+	}
+	return chunks
+}
+
+func countNumbers(messages []sms.Message) int {
+	n := 0
+	for _, m := range messages {
+		n += len(m.Numbers)
+	}
+	return n
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"fourjawaly-cli/fourjawaly/sms"
+)
+
+func writeTestFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// phones lists the numbers and sources of recipients for comparisons.
+func phones(recipients []recipient) []string {
+	out := []string{}
+	for _, r := range recipients {
+		out = append(out, r.Phone+"@"+r.Source[strings.LastIndex(r.Source, ":")+1:])
+	}
+	return out
+}
+
+func TestReadTextRecipients(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{name: "one per line", data: "0501234567\n0507654321\n", want: []string{"0501234567@1", "0507654321@2"}},
+		{name: "comma separated", data: "0501234567, 0507654321,,\n", want: []string{"0501234567@1", "0507654321@1"}},
+		{name: "blank lines and comments", data: "# list\n\n  0501234567  \n   \n# 0500000000\n", want: []string{"0501234567@3"}},
+		{name: "BOM and CRLF", data: "\ufeff0501234567\r\n0507654321\r\n", want: []string{"0501234567@1", "0507654321@2"}},
+		{name: "no trailing newline", data: "0501234567", want: []string{"0501234567@1"}},
+		{name: "empty", data: "", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readTextRecipients(writeTestFile(t, "list.txt", tt.data))
+			if err != nil {
+				t.Fatalf("readTextRecipients: %v", err)
+			}
+			if p := phones(got); !reflect.DeepEqual(p, tt.want) {
+				t.Errorf("recipients = %v, want %v", p, tt.want)
+			}
+			for _, r := range got {
+				if r.Fields["phone"] != r.Phone {
+					t.Errorf("Fields[phone] = %q, want %q", r.Fields["phone"], r.Phone)
+				}
+			}
+		})
+	}
+
+	if _, err := readTextRecipients(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("readTextRecipients on a missing file succeeded")
+	}
+}
+
+func TestReadCSVRecipients(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		phoneColumn string
+		want        []string
+		fields      map[string]string // fields of the first recipient
+		wantErr     bool
+	}{
+		{
+			name:   "phone column",
+			data:   "name,phone\nسارة,0501234567\nعلي,0507654321\n",
+			want:   []string{"0501234567@2", "0507654321@3"},
+			fields: map[string]string{"name": "سارة", "phone": "0501234567"},
+		},
+		{
+			name:   "BOM header",
+			data:   "\ufeffphone,name\n0501234567,سارة\n",
+			want:   []string{"0501234567@2"},
+			fields: map[string]string{"name": "سارة", "phone": "0501234567"},
+		},
+		{
+			name:   "detected mobile column, case-insensitive",
+			data:   "Name,Mobile\nسارة, 0501234567 \n",
+			want:   []string{"0501234567@2"},
+			fields: map[string]string{"Name": "سارة", "Mobile": "0501234567", "phone": "0501234567"},
+		},
+		{
+			name: "phone preferred over number",
+			data: "number,phone\n1,0501234567\n",
+			want: []string{"0501234567@2"},
+		},
+		{
+			name: "arabic header",
+			data: "الاسم,الجوال\nسارة,0501234567\n",
+			want: []string{"0501234567@2"},
+		},
+		{
+			name:        "--phone-column",
+			data:        "phone,whatsapp\n0500000000,0501234567\n",
+			phoneColumn: "WhatsApp",
+			want:        []string{"0501234567@2"},
+			fields:      map[string]string{"phone": "0501234567", "whatsapp": "0501234567"},
+		},
+		{
+			name:   "short rows",
+			data:   "phone,name,city\n0501234567\n0507654321,علي\n,سارة,جدة\n",
+			want:   []string{"0501234567@2", "0507654321@3"},
+			fields: map[string]string{"phone": "0501234567", "name": "", "city": ""},
+		},
+		{
+			name: "phone column beyond a short row",
+			data: "name,phone\nسارة\n",
+			want: []string{},
+		},
+		{
+			name:        "--phone-column not found",
+			data:        "phone\n0501234567\n",
+			phoneColumn: "mobile2",
+			wantErr:     true,
+		},
+		{name: "no phone column", data: "name,city\nسارة,جدة\n", wantErr: true},
+		{name: "empty file", data: "", wantErr: true},
+		{name: "bad quoting", data: "phone\n\"0501234567\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readCSVRecipients(writeTestFile(t, "list.csv", tt.data), tt.phoneColumn)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("readCSVRecipients = %v, want an error", phones(got))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readCSVRecipients: %v", err)
+			}
+			if p := phones(got); !reflect.DeepEqual(p, tt.want) {
+				t.Errorf("recipients = %v, want %v", p, tt.want)
+			}
+			if tt.fields != nil && !reflect.DeepEqual(got[0].Fields, tt.fields) {
+				t.Errorf("fields = %v, want %v", got[0].Fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestBuildSMSMessages(t *testing.T) {
+	recipients := []recipient{
+		{Phone: "966500000001", Fields: map[string]string{"phone": "966500000001", "name": "سارة", "city": "جدة"}, Source: "f:2"},
+		{Phone: "966500000002", Fields: map[string]string{"phone": "966500000002", "name": "علي", "city": "الرياض"}, Source: "f:3"},
+		{Phone: "966500000003", Fields: map[string]string{"phone": "966500000003", "name": "منى", "city": "جدة"}, Source: "f:4"},
+		{Phone: "966500000004", Fields: map[string]string{"phone": "966500000004", "name": "خالد", "city": "الرياض"}, Source: "f:5"},
+	}
+	tests := []struct {
+		name string
+		text string
+		want []sms.Message
+	}{
+		{
+			name: "plain text is one message",
+			text: "عرض اليوم",
+			want: []sms.Message{{Text: "عرض اليوم", Sender: "S", Numbers: []string{"966500000001", "966500000002", "966500000003", "966500000004"}}},
+		},
+		{
+			name: "identical renders are grouped in first-seen order",
+			text: "فرع {{.city}}",
+			want: []sms.Message{
+				{Text: "فرع جدة", Sender: "S", Numbers: []string{"966500000001", "966500000003"}},
+				{Text: "فرع الرياض", Sender: "S", Numbers: []string{"966500000002", "966500000004"}},
+			},
+		},
+		{
+			name: "surrounding space is trimmed before grouping",
+			text: "  {{if eq .city \"جدة\"}}جدة{{else}}غيرها{{end}}\n",
+			want: []sms.Message{
+				{Text: "جدة", Sender: "S", Numbers: []string{"966500000001", "966500000003"}},
+				{Text: "غيرها", Sender: "S", Numbers: []string{"966500000002", "966500000004"}},
+			},
+		},
+		{
+			name: "unique renders",
+			text: "{{.name}}",
+			want: []sms.Message{
+				{Text: "سارة", Sender: "S", Numbers: []string{"966500000001"}},
+				{Text: "علي", Sender: "S", Numbers: []string{"966500000002"}},
+				{Text: "منى", Sender: "S", Numbers: []string{"966500000003"}},
+				{Text: "خالد", Sender: "S", Numbers: []string{"966500000004"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildSMSMessages(tt.text, "S", recipients)
+			if err != nil {
+				t.Fatalf("buildSMSMessages: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildSMSMessages =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, text := range []string{"{{.missing}}", "{{.name"} {
+		if _, err := buildSMSMessages(text, "S", recipients); err == nil {
+			t.Errorf("buildSMSMessages(%q) succeeded, want an error", text)
+		}
+	}
+}
+
+func TestPackSMSChunks(t *testing.T) {
+	numbers := func(prefix string, n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = fmt.Sprintf("%s%03d", prefix, i)
+		}
+		return out
+	}
+	// shape describes a chunk as the number count of each of its messages.
+	shape := func(chunks [][]sms.Message) [][]int {
+		out := [][]int{}
+		for _, c := range chunks {
+			var counts []int
+			for _, m := range c {
+				counts = append(counts, len(m.Numbers))
+			}
+			out = append(out, counts)
+		}
+		return out
+	}
+	tests := []struct {
+		name     string
+		messages []sms.Message
+		size     int
+		want     [][]int
+	}{
+		{name: "empty", size: 3, want: [][]int{}},
+		{name: "exactly size", messages: []sms.Message{{Text: "a", Numbers: numbers("a", 3)}}, size: 3, want: [][]int{{3}}},
+		{name: "size+1", messages: []sms.Message{{Text: "a", Numbers: numbers("a", 4)}}, size: 3, want: [][]int{{3}, {1}}},
+		{name: "twice size", messages: []sms.Message{{Text: "a", Numbers: numbers("a", 6)}}, size: 3, want: [][]int{{3}, {3}}},
+		{
+			name:     "texts share a chunk",
+			messages: []sms.Message{{Text: "a", Numbers: numbers("a", 1)}, {Text: "b", Numbers: numbers("b", 2)}},
+			size:     3,
+			want:     [][]int{{1, 2}},
+		},
+		{
+			name:     "one text split across chunks",
+			messages: []sms.Message{{Text: "a", Numbers: numbers("a", 2)}, {Text: "b", Numbers: numbers("b", 5)}, {Text: "c", Numbers: numbers("c", 1)}},
+			size:     3,
+			want:     [][]int{{2, 1}, {3}, {1, 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := packSMSChunks(tt.messages, tt.size)
+			if got := shape(chunks); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("chunks = %v, want %v", got, tt.want)
+			}
+			// Every number is sent once, in order, under its own text.
+			var want, got []string
+			for _, m := range tt.messages {
+				for _, n := range m.Numbers {
+					want = append(want, m.Text+":"+n)
+				}
+			}
+			for _, c := range chunks {
+				if countNumbers(c) > tt.size {
+					t.Errorf("chunk of %d numbers, want at most %d", countNumbers(c), tt.size)
+				}
+				for _, m := range c {
+					for _, n := range m.Numbers {
+						got = append(got, m.Text+":"+n)
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("numbers = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDedupeRecipients(t *testing.T) {
+	r := func(n, source string) recipient {
+		return recipient{Phone: n, Fields: map[string]string{"phone": n}, Source: "f:" + source}
+	}
+	tests := []struct {
+		name    string
+		in      []recipient
+		want    []string
+		dropped int
+	}{
+		{name: "empty", in: nil, want: []string{}},
+		{name: "no repeats", in: []recipient{r("966500000001", "1"), r("966500000002", "2")}, want: []string{"966500000001@1", "966500000002@2"}},
+		{
+			name:    "first occurrence wins",
+			in:      []recipient{r("966500000001", "1"), r("966500000002", "2"), r("966500000001", "3"), r("966500000001", "4"), r("966500000002", "5")},
+			want:    []string{"966500000001@1", "966500000002@2"},
+			dropped: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, dropped := dedupeRecipients(tt.in)
+			if p := phones(got); !reflect.DeepEqual(p, tt.want) || dropped != tt.dropped {
+				t.Errorf("dedupeRecipients = %v, %d, want %v, %d", p, dropped, tt.want, tt.dropped)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fourjawaly-cli/fourjawaly"
+)
+
+// A report has one row per recipient of a send, so the failed subset can be
+// picked out and retried. The format follows the file extension: .jsonl,
+// .ndjson and .json give JSON lines, anything else CSV.
+
+var reportHeader = []string{"number", "chunk", "status", "http_status", "job_id", "err_text"}
+
+type reportRow struct {
+	Number     string `json:"number"`
+	Chunk      int    `json:"chunk"`
+	Status     string `json:"status"`
+	HTTPStatus int    `json:"http_status,omitempty"`
+	JobID      string `json:"job_id,omitempty"`
+	ErrText    string `json:"err_text,omitempty"`
+}
+
+type reportWriter struct {
+	mu    sync.Mutex
+	f     *os.File
+	csv   *csv.Writer
+	jsonl bool
+}
+
+func createReport(path string) (*reportWriter, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, validationErrorf("تعذر إنشاء ملف التقرير: %v", err)
+	}
+	r := &reportWriter{f: f}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".json":
+		r.jsonl = true
+	default:
+		r.csv = csv.NewWriter(f)
+		if err := r.csv.Write(reportHeader); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Write records every number of a chunk. It is a no-op on a nil writer, and
+// like the journal a failed write is reported without stopping the send.
+func (r *reportWriter) Write(cr chunkResult) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var err error
+	for _, row := range reportRows(cr) {
+		if err = r.writeRow(row); err != nil {
+			break
+		}
+	}
+	if err == nil && r.csv != nil {
+		r.csv.Flush()
+		err = r.csv.Error()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "تحذير: تعذر الكتابة في ملف التقرير: %v\n", err)
+	}
+}
+
+func (r *reportWriter) writeRow(row reportRow) error {
+	if r.jsonl {
+		line, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		_, err = r.f.Write(append(line, '\n'))
+		return err
+	}
+	httpStatus := ""
+	if row.HTTPStatus != 0 {
+		httpStatus = strconv.Itoa(row.HTTPStatus)
+	}
+	return r.csv.Write([]string{row.Number, strconv.Itoa(row.Chunk), row.Status, httpStatus, row.JobID, row.ErrText})
+}
+
+func (r *reportWriter) Close() error {
+	if r == nil {
+		return nil
+	}
+	return r.f.Close()
+}
+
+// reportRows expands a chunk result per number. The API answers with one
+// messages entry per request message, so when the lengths line up each
+// number gets the err_text of its own message.
+func reportRows(cr chunkResult) []reportRow {
+	outcome := classifyChunk(cr)
+	base := reportRow{Chunk: cr.Index, Status: outcome.String(), HTTPStatus: cr.StatusCode}
+	var apiErr *fourjawaly.APIError
+	if errors.As(cr.Error, &apiErr) {
+		base.HTTPStatus = apiErr.StatusCode
+		base.ErrText = apiErr.Message
+	} else if cr.Error != nil {
+		base.ErrText = cr.Error.Error()
+	}
+	aligned := false
+	if cr.Result != nil {
+		base.JobID = cr.Result.JobID.String()
+		aligned = len(cr.Result.Messages) == len(cr.Messages)
+		if outcome == outcomeUnknown && base.ErrText == "" {
+			base.ErrText = "رد بدون messages"
+		}
+	}
+
+	rows := make([]reportRow, 0, len(cr.Numbers))
+	for i, m := range cr.Messages {
+		row := base
+		if aligned && cr.Result.Messages[i].ErrText != "" {
+			row.ErrText = cr.Result.Messages[i].ErrText
+		}
+		for _, n := range m.Numbers {
+			row.Number = n
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// readReportJobIDs returns the distinct job_ids of a --report file, in the
+// order they first appear.
+func readReportJobIDs(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, validationErrorf("تعذر فتح ملف التقرير: %v", err)
+	}
+	defer f.Close()
+
+	var ids []string
+	seen := map[string]bool{}
+	add := func(id string) {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".json":
+		dec := json.NewDecoder(f)
+		for {
+			var row reportRow
+			if err := dec.Decode(&row); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, validationErrorf("%s: %v", path, err)
+			}
+			add(row.JobID)
+		}
+	default:
+		r := csv.NewReader(f)
+		r.FieldsPerRecord = -1
+		header, err := r.Read()
+		if err != nil {
+			return nil, validationErrorf("%s: %v", path, err)
+		}
+		col := slices.Index(header, "job_id")
+		if col < 0 {
+			return nil, validationErrorf("%s: لا يوجد عمود job_id", path)
+		}
+		for {
+			rec, err := r.Read()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, validationErrorf("%s: %v", path, err)
+			}
+			if col < len(rec) {
+				add(rec[col])
+			}
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"fourjawaly-cli/fourjawaly/sms"
+)
+
+// Scheduling modes for --send-at: hand the time to the API, or keep the
+// process waiting and send when it comes.
+const (
+	scheduleAPI  = "api"
+	scheduleWait = "wait"
+)
+
+// sendAtLayouts are the accepted --send-at forms without an offset; they are
+// read in --tz.
+var sendAtLayouts = []string{
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+}
+
+// scheduleSkew is how far in the past --send-at may be, to allow for the
+// time it takes to type the command.
+const scheduleSkew = time.Minute
+
+type scheduleFlags struct {
+	SendAt string
+	TZ     string
+	Mode   string
+}
+
+func addScheduleFlags(fs *flag.FlagSet) *scheduleFlags {
+	f := &scheduleFlags{}
+	fs.StringVar(&f.SendAt, "send-at", "", "موعد الإرسال مثل 2026-10-20T09:00")
+	fs.StringVar(&f.TZ, "tz", "", "المنطقة الزمنية لـ --send-at مثل Asia/Riyadh (أو FOURJAWALY_TZ، الافتراضي توقيت الجهاز)")
+	fs.StringVar(&f.Mode, "schedule-mode", scheduleAPI, "api: جدولة عبر الـ API، wait: الانتظار محليًا ثم الإرسال")
+	return f
+}
+
+// resolve parses --send-at in --tz. It returns the zero time when no
+// schedule was asked for.
+func (f *scheduleFlags) resolve(now time.Time) (time.Time, error) {
+	value := strings.TrimSpace(f.SendAt)
+	if value == "" {
+		if strings.TrimSpace(f.TZ) != "" {
+			return time.Time{}, usageErrorf("--tz يحتاج --send-at")
+		}
+		return time.Time{}, nil
+	}
+	switch f.Mode {
+	case scheduleAPI, scheduleWait:
+	default:
+		return time.Time{}, usageErrorf("--schedule-mode غير معروف %q (المتاح: api, wait)", f.Mode)
+	}
+
+	loc := time.Local
+	if tz := firstNonEmpty(f.TZ, envOrDefault("FOURJAWALY_TZ", "")); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return time.Time{}, usageErrorf("--tz: منطقة زمنية غير معروفة %q", tz)
+		}
+	}
+
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		for _, layout := range sendAtLayouts {
+			if at, err = time.ParseInLocation(layout, value, loc); err == nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return time.Time{}, usageErrorf("--send-at: موعد غير صحيح %q (مثال: 2026-10-20T09:00)", value)
+	}
+	if at.Before(now.Add(-scheduleSkew)) {
+		return time.Time{}, validationErrorf("--send-at %s في الماضي", at.In(loc).Format(time.DateTime))
+	}
+	return at.In(loc), nil
+}
+
+// scheduleMessages stamps every message with the send time, in the API's
+// date format and the chosen zone.
+func scheduleMessages(messages []sms.Message, at time.Time) {
+	// "Local" means nothing to the API.
+	if at.Location() == time.Local {
+		at = at.UTC()
+	}
+	for i := range messages {
+		messages[i].SendAt = at.Format(time.DateTime)
+		messages[i].Timezone = at.Location().String()
+	}
+}
+
+// waitUntil blocks until at, reporting progress, and returns errInterrupted
+// if the user stops the wait.
+func waitUntil(at time.Time) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	d := time.Until(at)
+	if d <= 0 {
+		return nil
+	}
+	progressf("بانتظار موعد الإرسال %s (بعد %s)، Ctrl+C للإلغاء...\n", at.Format(time.DateTime+" MST"), d.Round(time.Second))
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return errInterrupted
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestSMSSendWaitRechecks sends with a local --send-at wait: the sender and
+// the balance are checked when queued and again when the wait ends, so a
+// balance spent in the meantime stops the send.
+func TestSMSSendWaitRechecks(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		balances []float64 // balance at each check
+		err      any
+		sends    int32
+	}{
+		{name: "still enough", balances: []float64{100, 100}, sends: 1},
+		{name: "spent while waiting", balances: []float64{100, 0}, err: new(*insufficientBalanceError)},
+		{name: "short when queued", balances: []float64{0}, err: new(*insufficientBalanceError)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			setupCommandEnv(t)
+			var balanceCalls, senderCalls, sends atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body any
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/me/packages"):
+					n := int(balanceCalls.Add(1))
+					body = map[string]any{"code": 200, "total_balance": tt.balances[min(n, len(tt.balances))-1]}
+				case strings.HasSuffix(r.URL.Path, "/senders"):
+					senderCalls.Add(1)
+					body = map[string]any{"code": 200, "items": map[string]any{"current_page": 1, "last_page": 1, "data": []map[string]any{
+						{"id": 1, "sender_name": "S", "status": 1},
+					}}}
+				case strings.HasSuffix(r.URL.Path, "/sms/send"):
+					sends.Add(1)
+					body = map[string]any{"code": 200, "job_id": 1, "messages": []map[string]any{{"inserted_numbers": 1}}}
+				default:
+					http.NotFound(w, r)
+					return
+				}
+				json.NewEncoder(w).Encode(body)
+			}))
+			defer srv.Close()
+
+			sendAt := time.Now().Add(200 * time.Millisecond).Format(time.RFC3339Nano)
+			err := runSMSSend([]string{"--sender", "S", "--to", "0501234567", "--message", "hi", "--require-balance",
+				"--send-at", sendAt, "--schedule-mode", "wait", "--no-journal", "--base-url", srv.URL, "--output", "quiet"})
+			if tt.err == nil && err != nil {
+				t.Fatalf("runSMSSend: %v", err)
+			}
+			if tt.err != nil && !errors.As(err, tt.err) {
+				t.Fatalf("runSMSSend = %v, want %T", err, tt.err)
+			}
+			if got := sends.Load(); got != tt.sends {
+				t.Errorf("sends = %d, want %d", got, tt.sends)
+			}
+			if want := int32(len(tt.balances)); balanceCalls.Load() != want || senderCalls.Load() != want {
+				t.Errorf("balance, sender checks = %d, %d, want %d each", balanceCalls.Load(), senderCalls.Load(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"strings"
+)
+
+// apiSecretFile is set by --api-secret-file, which every command taking
+// --api-secret also accepts.
+var apiSecretFile string
+
+func addSecretFileFlag(fs *flag.FlagSet) {
+	fs.StringVar(&apiSecretFile, "api-secret-file", "", "ملف يحتوي سر API (أو FOURJAWALY_API_SECRET_FILE)")
+}
+
+var plainSecretWarned bool
+
+// warnPlainSecret warns, once per run, that --api-secret is exposed.
+func warnPlainSecret() {
+	if plainSecretWarned {
+		return
+	}
+	plainSecretWarned = true
+	progressf("تحذير: --api-secret يظهر في قائمة العمليات و history، استخدم --api-secret-file أو --api-secret - أو auth login\n")
+}
+
+// stdinSecret keeps what --api-secret - read, since stdin can be read only
+// once but the secret may be resolved more than once.
+var stdinSecret string
+
+// readSecretFile reads a secret mounted as a file, Docker/Kubernetes style;
+// the trailing newline most editors add is dropped.
+func readSecretFile(path, source string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", validationErrorf("%s: %v", source, err)
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", validationErrorf("%s: الملف %s فارغ", source, path)
+	}
+	return secret, nil
+}
+
+// readSecretStdin reads --api-secret - from the first line of stdin, asking
+// for it without echo when stdin is a terminal.
+func readSecretStdin() (string, error) {
+	if stdinSecret != "" {
+		return stdinSecret, nil
+	}
+	var secret string
+	if isTerminal(os.Stdin) {
+		var err error
+		if secret, err = promptLine("API secret", true); err != nil {
+			return "", err
+		}
+	} else {
+		line, err := stdin.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", validationErrorf("--api-secret -: %v", err)
+		}
+		secret = strings.TrimSpace(line)
+	}
+	if secret == "" {
+		return "", validationErrorf("--api-secret -: لم يُقرأ سر من stdin")
+	}
+	stdinSecret = secret
+	return secret, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"unicode/utf8"
+
+	"fourjawaly-cli/fourjawaly/sms"
+)
+
+// maxSenderNameLength is the GSM limit for alphanumeric sender IDs.
+const maxSenderNameLength = 11
+
+func runSMSSendersRequest(args []string) error {
+	fs := flag.NewFlagSet("sms senders request", flag.ContinueOnError)
+	appKeyFlag := fs.String("app-key", "", "مفتاح API")
+	apiSecretFlag := fs.String("api-secret", "", "سر API (أو - للقراءة من stdin)")
+	addSecretFileFlag(fs)
+	nameFlag := fs.String("name", "", "اسم المرسل المطلوب")
+	reasonFlag := fs.String("reason", "", "سبب الطلب أو وصف الاستخدام")
+	dryRun := fs.Bool("dry-run", false, "معاينة بدون إرسال")
+	baseURLFlag := fs.String("base-url", "", "رابط API (الافتراضي: "+defaultSMSBaseURL+")")
+	addGlobalFlags(fs)
+	addRetryFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := resolveSMSConfig(*appKeyFlag, *apiSecretFlag, *baseURLFlag)
+	if err != nil {
+		return err
+	}
+
+	name := trimFlag(nameFlag)
+	if err := requireNonEmpty(name, "--name"); err != nil {
+		return err
+	}
+	if err := requireNonEmpty(trimFlag(reasonFlag), "--reason"); err != nil {
+		return err
+	}
+	if n := utf8.RuneCountInString(name); n > maxSenderNameLength {
+		return validationErrorf("اسم المرسل %q أطول من %d حرفًا (%d)", name, maxSenderNameLength, n)
+	}
+
+	client := cfg.client()
+	req := sms.SenderRequest{SenderName: name, Note: trimFlag(reasonFlag)}
+	if *dryRun {
+		return dryRunPrint(http.MethodPost, client.BaseURL()+"/account/area/senders", req)
+	}
+
+	ctx := context.Background()
+	existing, err := client.FindSender(ctx, name)
+	if err != nil {
+		return reportAPIError(err)
+	}
+	if existing != nil {
+		return validationErrorf("اسم المرسل %q موجود مسبقًا بالحالة %s", existing.SenderName, existing.State())
+	}
+
+	res, err := client.RequestSender(ctx, req)
+	if err != nil {
+		return reportAPIError(err)
+	}
+	progressf("تم إرسال طلب اسم المرسل %q، وسيظهر بالحالة pending حتى المراجعة\n", name)
+	return emitResult(res.Meta, nil)
+}
+
+func runSMSSendersShow(args []string) error {
+	fs := flag.NewFlagSet("sms senders show", flag.ContinueOnError)
+	appKeyFlag := fs.String("app-key", "", "مفتاح API")
+	apiSecretFlag := fs.String("api-secret", "", "سر API (أو - للقراءة من stdin)")
+	addSecretFileFlag(fs)
+	baseURLFlag := fs.String("base-url", "", "رابط API (الافتراضي: "+defaultSMSBaseURL+")")
+	addGlobalFlags(fs)
+	addRetryFlags(fs)
+	positional, err := parseFlagsWithArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageErrorf("الاستخدام: 4jawaly-cli sms senders show <اسم المرسل>")
+	}
+	name := positional[0]
+
+	cfg, err := resolveSMSConfig(*appKeyFlag, *apiSecretFlag, *baseURLFlag)
+	if err != nil {
+		return err
+	}
+
+	snd, err := cfg.client().FindSender(context.Background(), name)
+	if err != nil {
+		return reportAPIError(err)
+	}
+	if snd == nil {
+		return validationErrorf("اسم المرسل %q غير موجود في الحساب", name)
+	}
+
+	t := sendersTable(&sms.SendersResult{Senders: sms.Collection[sms.Sender]{Data: []sms.Sender{*snd}}})
+	t.header = append(t.header, "state")
+	t.rows[0] = append(t.rows[0], snd.State())
+	return emitValue(map[string]any{
+		"id":          snd.ID,
+		"sender_name": snd.SenderName,
+		"status":      snd.Status,
+		"state":       snd.State(),
+		"is_default":  snd.IsDefault,
+		"note":        snd.Note,
+	}, t)
+}
+
+// warnUnapprovedSender checks the sender name before a send so that a typo
+// or a pending name is caught before credits are spent. It only warns: the
+// send goes ahead, and a failed check is not an error.
+func warnUnapprovedSender(cfg smsConfig, name string) {
+	snd, err := cfg.client().FindSender(context.Background(), name)
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "تحذير: تعذر التحقق من اسم المرسل: %v\n", err)
+	case snd == nil:
+		fmt.Fprintf(os.Stderr, "تحذير: اسم المرسل %q غير موجود في الحساب\n", name)
+	case !snd.Approved():
+		fmt.Fprintf(os.Stderr, "تحذير: اسم المرسل %q غير معتمد (الحالة: %s)\n", snd.SenderName, snd.State())
+	}
+}
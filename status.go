@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"fourjawaly-cli/fourjawaly"
+	"fourjawaly-cli/fourjawaly/sms"
+)
+
+// statusPageSize is the page size used to walk the messages of a job.
+const statusPageSize = 100
+
+var errWatchTimeout = errors.New("انتهت مهلة --watch قبل وصول كل الرسائل لحالة نهائية")
+
+func runSMSStatus(args []string) error {
+	fs := flag.NewFlagSet("sms status", flag.ContinueOnError)
+	appKeyFlag := fs.String("app-key", "", "مفتاح API")
+	apiSecretFlag := fs.String("api-secret", "", "سر API (أو - للقراءة من stdin)")
+	addSecretFileFlag(fs)
+	jobIDFlag := fs.String("job-id", "", "رقم أو أكثر من job_id مفصولة بفاصلة")
+	fromReportFlag := fs.String("from-report", "", "قراءة job_id من ملف --report")
+	watch := fs.Bool("watch", false, "إعادة الاستعلام حتى تصل كل الرسائل لحالة نهائية")
+	interval := fs.Duration("interval", 30*time.Second, "الفترة بين الاستعلامات مع --watch")
+	timeout := fs.Duration("timeout", 30*time.Minute, "أقصى مدة للمتابعة مع --watch (0 بلا حد)")
+	baseURLFlag := fs.String("base-url", "", "رابط API (الافتراضي: "+defaultSMSBaseURL+")")
+	addGlobalFlags(fs)
+	addRetryFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *interval <= 0 {
+		return usageErrorf("--interval يجب أن يكون أكبر من صفر")
+	}
+
+	if err := requireNonEmpty(trimFlag(jobIDFlag)+trimFlag(fromReportFlag), "--job-id أو --from-report"); err != nil {
+		return err
+	}
+	jobIDs := splitAndCleanCSV(*jobIDFlag)
+	if path := trimFlag(fromReportFlag); path != "" {
+		ids, err := readReportJobIDs(path)
+		if err != nil {
+			return err
+		}
+		jobIDs = append(jobIDs, ids...)
+	}
+	jobIDs = dedupeStrings(jobIDs)
+	if len(jobIDs) == 0 {
+		return validationErrorf("لا يوجد job_id في %s", trimFlag(fromReportFlag))
+	}
+
+	cfg, err := resolveSMSConfig(*appKeyFlag, *apiSecretFlag, *baseURLFlag)
+	if err != nil {
+		return err
+	}
+	client := cfg.client()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var deadline time.Time
+	if *timeout > 0 {
+		deadline = time.Now().Add(*timeout)
+	}
+
+	for {
+		messages, err := jobMessages(ctx, client, jobIDs)
+		if err != nil {
+			if ctx.Err() != nil {
+				return errInterrupted
+			}
+			return reportAPIError(err)
+		}
+		open := 0
+		for _, m := range messages {
+			if !m.State().Final() {
+				open++
+			}
+		}
+		// An empty job is not done: right after a send the API may not
+		// have indexed it yet.
+		if !*watch || (len(messages) > 0 && open == 0) {
+			return emitStatus(jobIDs, messages)
+		}
+		if !deadline.IsZero() && time.Now().Add(*interval).After(deadline) {
+			if err := emitStatus(jobIDs, messages); err != nil {
+				return err
+			}
+			return errWatchTimeout
+		}
+		if len(messages) == 0 {
+			progressf("لا توجد رسائل للـ job بعد، الاستعلام التالي بعد %s\n", *interval)
+		} else {
+			progressf("%d من %d رقم لم تصل لحالة نهائية، الاستعلام التالي بعد %s\n", open, len(messages), *interval)
+		}
+		select {
+		case <-ctx.Done():
+			if err := emitStatus(jobIDs, messages); err != nil {
+				return err
+			}
+			return errInterrupted
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// jobMessages fetches every page of messages for each job.
+func jobMessages(ctx context.Context, client *sms.Client, jobIDs []string) ([]sms.SentMessage, error) {
+	var all []sms.SentMessage
+	for _, id := range jobIDs {
+		messages, err := sms.NewPager(func(ctx context.Context, page int) (sms.Collection[sms.SentMessage], error) {
+			res, err := client.Messages(ctx, sms.MessageQuery{JobID: id, Page: page, PageSize: statusPageSize})
+			if err != nil {
+				return sms.Collection[sms.SentMessage]{}, err
+			}
+			return res.Messages, nil
+		}).All(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range messages {
+			if m.JobID == "" {
+				m.JobID = fourjawaly.FlexString(id)
+			}
+			all = append(all, m)
+		}
+	}
+	return all, nil
+}
+
+type statusRow struct {
+	Number      string            `json:"number"`
+	JobID       string            `json:"job_id"`
+	State       sms.DeliveryState `json:"state"`
+	Status      string            `json:"status,omitempty"`
+	SentAt      string            `json:"sent_at,omitempty"`
+	DeliveredAt string            `json:"delivered_at,omitempty"`
+}
+
+func emitStatus(jobIDs []string, messages []sms.SentMessage) error {
+	states := map[sms.DeliveryState]int{}
+	rows := make([]statusRow, 0, len(messages))
+	t := &table{header: []string{"number", "job_id", "state", "status", "sent_at", "delivered_at"}}
+	for _, m := range messages {
+		state := m.State()
+		states[state]++
+		status := firstNonEmpty(m.StatusText, m.Status.String())
+		rows = append(rows, statusRow{
+			Number:      m.Number.String(),
+			JobID:       m.JobID.String(),
+			State:       state,
+			Status:      status,
+			SentAt:      m.CreatedAt,
+			DeliveredAt: m.DeliveredAt,
+		})
+		t.rows = append(t.rows, []string{m.Number.String(), m.JobID.String(), string(state), status, m.CreatedAt, m.DeliveredAt})
+	}
+
+	summary := ""
+	for _, s := range []sms.DeliveryState{sms.StateDelivered, sms.StateFailed, sms.StateSent, sms.StatePending, sms.StateUnknown} {
+		if states[s] > 0 {
+			summary += fmt.Sprintf(" %s=%d", s, states[s])
+		}
+	}
+	progressf("%d رقم في %d job:%s\n", len(messages), len(jobIDs), summary)
+
+	return emitValue(map[string]any{
+		"job_ids":  jobIDs,
+		"states":   states,
+		"messages": rows,
+	}, t)
+}
+
+func dedupeStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := values[:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// statusServer answers the messages endpoint with one poll per entry of
+// polls, repeating the last one; each entry is the status of every number.
+func statusServer(t *testing.T, polls ...[]string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		statuses := polls[min(n, len(polls))-1]
+		data := []map[string]any{}
+		for i, status := range statuses {
+			data = append(data, map[string]any{"id": i + 1, "number": fmt.Sprintf("9665000000%02d", i+1), "status": status})
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"code":  200,
+			"items": map[string]any{"current_page": 1, "last_page": 1, "data": data},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+// setupCommandEnv isolates a command from the user's config and gives it
+// credentials, restoring the output mode afterwards.
+func setupCommandEnv(t *testing.T) {
+	t.Helper()
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(config, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FOURJAWALY_CONFIG", config)
+	t.Setenv("FOURJAWALY_APP_KEY", "k")
+	t.Setenv("FOURJAWALY_API_SECRET", "s")
+	prev := output
+	t.Cleanup(func() { output = prev })
+}
+
+func TestSMSStatusWatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		polls [][]string
+		calls int
+		err   error
+	}{
+		{name: "final on the first poll", polls: [][]string{{"delivered", "failed"}}, calls: 1},
+		{name: "empty first poll", polls: [][]string{{}, {"delivered"}}, calls: 2},
+		{name: "unknown state", polls: [][]string{{"delivered", "mystery"}, {"delivered", "delivered"}}, calls: 2},
+		{name: "empty then unknown", polls: [][]string{{}, {"mystery"}, {"sent"}, {"delivered"}}, calls: 4},
+		{name: "empty until the timeout", polls: [][]string{{}}, err: errWatchTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupCommandEnv(t)
+			srv, calls := statusServer(t, tt.polls...)
+			args := []string{"--job-id", "42", "--watch", "--interval", "1ms", "--base-url", srv.URL, "--output", "quiet"}
+			if tt.err != nil {
+				args = append(args, "--timeout", "20ms")
+			}
+			err := runSMSStatus(args)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("runSMSStatus = %v, want %v", err, tt.err)
+			}
+			if tt.err == nil && int(calls.Load()) != tt.calls {
+				t.Errorf("polls = %d, want %d", calls.Load(), tt.calls)
+			}
+			if tt.err != nil && calls.Load() < 2 {
+				t.Errorf("polls = %d, want polling to continue until the timeout", calls.Load())
+			}
+		})
+	}
+}
+
+func TestSMSStatusNoWatch(t *testing.T) {
+	setupCommandEnv(t)
+	srv, calls := statusServer(t, []string{})
+	if err := runSMSStatus([]string{"--job-id", "42", "--base-url", srv.URL, "--output", "quiet"}); err != nil {
+		t.Fatalf("runSMSStatus: %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("polls = %d, want 1 without --watch", calls.Load())
+	}
+}
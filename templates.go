@@ -0,0 +1,330 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// Channels a saved template may be used on.
+const (
+	channelSMS = "sms"
+	channelWA  = "wa"
+	channelAll = "all"
+)
+
+var templateNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// savedTemplate is one named message kept by template add.
+type savedTemplate struct {
+	Name        string    `json:"name"`
+	Channel     string    `json:"channel"`
+	Text        string    `json:"text"`
+	Description string    `json:"description,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// For reports whether the template may be sent on channel.
+func (t savedTemplate) For(channel string) bool {
+	return t.Channel == channelAll || t.Channel == channel
+}
+
+// templateStore is the templates file in the config directory, keyed by
+// name.
+type templateStore struct {
+	path string
+}
+
+func newTemplateStore() (*templateStore, error) {
+	if path := envOrDefault("FOURJAWALY_TEMPLATES_FILE", ""); path != "" {
+		return &templateStore{path: path}, nil
+	}
+	dir, err := userConfigDir()
+	if err != nil {
+		return nil, validationErrorf("تعذر تحديد مجلد الإعدادات: %v", err)
+	}
+	return &templateStore{path: filepath.Join(dir, "templates.json")}, nil
+}
+
+func (s *templateStore) read() (map[string]savedTemplate, error) {
+	all := map[string]savedTemplate{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, validationErrorf("تعذر قراءة ملف القوالب: %v", err)
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, validationErrorf("%s تالف: %v", s.path, err)
+	}
+	return all, nil
+}
+
+func (s *templateStore) write(all map[string]savedTemplate) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *templateStore) get(name string) (savedTemplate, error) {
+	all, err := s.read()
+	if err != nil {
+		return savedTemplate{}, err
+	}
+	t, ok := all[name]
+	if !ok {
+		return savedTemplate{}, validationErrorf("القالب %q غير موجود (راجع: 4jawaly-cli template list)", name)
+	}
+	return t, nil
+}
+
+func (s *templateStore) list() ([]savedTemplate, error) {
+	all, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]savedTemplate, 0, len(all))
+	for _, t := range all {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// parseMessageTemplate parses text the way sms send renders --message.
+func parseMessageTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(text)
+}
+
+// templateFields lists the {{.name}} and {{$.name}} placeholders of t, sorted. Required
+// ones are printed unconditionally; optional ones appear only in the pipeline
+// or body of an if, with or range, so the message renders without them.
+func templateFields(t *template.Template) (required, optional []string) {
+	seen := map[string]bool{} // name -> required
+	var walk func(n parse.Node, conditional bool)
+	walk = func(n parse.Node, conditional bool) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c, conditional)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe, conditional)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd, conditional)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg, conditional)
+			}
+		case *parse.FieldNode:
+			seen[n.Ident[0]] = seen[n.Ident[0]] || !conditional
+		case *parse.VariableNode:
+			// $.name reaches the top-level data from anywhere, including
+			// inside with and range; other variables were declared from a
+			// pipeline that is walked on its own.
+			if n.Ident[0] == "$" && len(n.Ident) > 1 {
+				seen[n.Ident[1]] = seen[n.Ident[1]] || !conditional
+			}
+		case *parse.ChainNode:
+			walk(n.Node, conditional)
+		case *parse.IfNode:
+			walk(n.Pipe, true)
+			walk(n.List, true)
+			walk(n.ElseList, true)
+		case *parse.RangeNode:
+			walk(n.Pipe, true)
+			walk(n.List, true)
+			walk(n.ElseList, true)
+		case *parse.WithNode:
+			walk(n.Pipe, true)
+			walk(n.List, true)
+			walk(n.ElseList, true)
+		}
+	}
+	if t.Tree != nil {
+		walk(t.Tree.Root, false)
+	}
+	required, optional = []string{}, []string{}
+	for f, req := range seen {
+		if req {
+			required = append(required, f)
+		} else {
+			optional = append(optional, f)
+		}
+	}
+	sort.Strings(required)
+	sort.Strings(optional)
+	return required, optional
+}
+
+// withOptionalFields returns values with every optional placeholder that has
+// no entry set to "", so that missingkey=error only trips on required ones.
+// values itself is not modified.
+func withOptionalFields(values map[string]string, optional []string) map[string]string {
+	var out map[string]string
+	for _, f := range optional {
+		if _, ok := values[f]; ok {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string, len(values)+len(optional))
+			for k, v := range values {
+				out[k] = v
+			}
+		}
+		out[f] = ""
+	}
+	if out == nil {
+		return values
+	}
+	return out
+}
+
+// missingFields returns the placeholders with no value, or an empty one, in
+// values.
+func missingFields(placeholders []string, values map[string]string) []string {
+	var missing []string
+	for _, f := range placeholders {
+		if strings.TrimSpace(values[f]) == "" {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// templateVars collects repeated --var key=value flags.
+type templateVars map[string]string
+
+func (v templateVars) String() string {
+	pairs := make([]string, 0, len(v))
+	for k, val := range v {
+		pairs = append(pairs, k+"="+val)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (v templateVars) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return fmt.Errorf("متوقع key=value: %q", s)
+	}
+	v[key] = value
+	return nil
+}
+
+// messageTemplateFlags are --template and --var, which send commands accept
+// in place of --message.
+type messageTemplateFlags struct {
+	Name string
+	Vars templateVars
+}
+
+func addMessageTemplateFlags(fs *flag.FlagSet) *messageTemplateFlags {
+	f := &messageTemplateFlags{Vars: templateVars{}}
+	fs.StringVar(&f.Name, "template", "", "اسم قالب محفوظ بدل --message (راجع: template list)")
+	fs.Var(f.Vars, "var", "قيمة لمتغير في القالب key=value (يتكرر)")
+	return f
+}
+
+// load returns the text of --template for channel, or message when no
+// template was given.
+func (f *messageTemplateFlags) load(message, channel string) (string, error) {
+	name := strings.TrimSpace(f.Name)
+	if name == "" {
+		if len(f.Vars) > 0 {
+			return "", usageErrorf("--var يحتاج --template")
+		}
+		return message, nil
+	}
+	if message != "" {
+		return "", usageErrorf("--message و --template لا يجتمعان")
+	}
+	store, err := newTemplateStore()
+	if err != nil {
+		return "", err
+	}
+	t, err := store.get(name)
+	if err != nil {
+		return "", err
+	}
+	if !t.For(channel) {
+		return "", validationErrorf("القالب %q مخصص لـ %s وليس %s", name, t.Channel, channel)
+	}
+	return t.Text, nil
+}
+
+// render fills text with --var values for a single message, failing on any
+// placeholder left without a value.
+func (f *messageTemplateFlags) render(text string) (string, error) {
+	tmpl, err := parseMessageTemplate("message", text)
+	if err != nil {
+		return "", validationErrorf("قالب غير صحيح: %v", err)
+	}
+	required, optional := templateFields(tmpl)
+	if missing := missingFields(required, f.Vars); len(missing) > 0 {
+		return "", validationErrorf("قيم ناقصة في القالب: %s (مرّر --var %s=...)", strings.Join(missing, ", "), missing[0])
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, withOptionalFields(f.Vars, optional)); err != nil {
+		return "", validationErrorf("تعذر تعبئة القالب: %v", err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// apply merges --var values into each recipient's fields, columns from
+// --to-file taking precedence, and checks that every required placeholder of
+// the template is filled for every recipient. Plain --message is left as it
+// was.
+func (f *messageTemplateFlags) apply(text string, recipients []recipient) error {
+	if strings.TrimSpace(f.Name) == "" || !strings.Contains(text, "{{") {
+		return nil
+	}
+	tmpl, err := parseMessageTemplate("message", text)
+	if err != nil {
+		return validationErrorf("قالب --message غير صحيح: %v", err)
+	}
+	placeholders, _ := templateFields(tmpl)
+	for i := range recipients {
+		r := &recipients[i]
+		for k, v := range f.Vars {
+			if strings.TrimSpace(r.Fields[k]) == "" {
+				r.Fields[k] = v
+			}
+		}
+		if missing := missingFields(placeholders, r.Fields); len(missing) > 0 {
+			return validationErrorf("قيم ناقصة في القالب للرقم %s (%s): %s (مرّر --var %s=... أو أضف العمود)",
+				r.Phone, r.Source, strings.Join(missing, ", "), missing[0])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTemplateFields(t *testing.T) {
+	tests := []struct {
+		text     string
+		required []string
+		optional []string
+	}{
+		{text: "plain text", required: []string{}, optional: []string{}},
+		{text: "رمز {{.code}} صالح {{.minutes}} دقائق {{.code}}", required: []string{"code", "minutes"}, optional: []string{}},
+		{text: "مرحبا{{if .name}} {{.name}}{{end}}", required: []string{}, optional: []string{"name"}},
+		{text: "{{with .coupon}}كود {{.}}{{else}}{{.fallback}}{{end}}", required: []string{}, optional: []string{"coupon", "fallback"}},
+		{text: "{{range .items}}-{{end}}", required: []string{}, optional: []string{"items"}},
+		// Used unconditionally anywhere makes it required.
+		{text: "{{if .name}}أهلًا{{end}} {{.name}}", required: []string{"name"}, optional: []string{}},
+		{text: "{{.total | printf \"%s ريال\"}}{{if eq .tier \"gold\"}} ⭐{{end}}", required: []string{"total"}, optional: []string{"tier"}},
+		// $.name is the same placeholder, and still top-level inside with.
+		{text: "{{$.name}} {{if $.vip}}⭐{{end}}", required: []string{"name"}, optional: []string{"vip"}},
+		{text: "{{with .coupon}}{{$.name}}: {{.}}{{end}}", required: []string{}, optional: []string{"coupon", "name"}},
+		{text: "{{$code := .code}}{{$code}} {{($.user).name}}", required: []string{"code", "user"}, optional: []string{}},
+	}
+	for _, tt := range tests {
+		tmpl, err := parseMessageTemplate("t", tt.text)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.text, err)
+		}
+		required, optional := templateFields(tmpl)
+		if !reflect.DeepEqual(required, tt.required) || !reflect.DeepEqual(optional, tt.optional) {
+			t.Errorf("templateFields(%q) = %v, %v, want %v, %v", tt.text, required, optional, tt.required, tt.optional)
+		}
+	}
+}
+
+func TestMessageTemplateRenderOptional(t *testing.T) {
+	const text = "مرحبا{{if .name}} {{.name}}{{end}}، رمزك {{.code}}"
+	tests := []struct {
+		vars    templateVars
+		want    string
+		wantErr bool
+	}{
+		{vars: templateVars{"code": "1234"}, want: "مرحبا، رمزك 1234"},
+		{vars: templateVars{"code": "1234", "name": "سارة"}, want: "مرحبا سارة، رمزك 1234"},
+		{vars: templateVars{"name": "سارة"}, wantErr: true},
+		{vars: templateVars{"code": " "}, wantErr: true},
+	}
+	for _, tt := range tests {
+		f := &messageTemplateFlags{Name: "otp", Vars: tt.vars}
+		got, err := f.render(text)
+		if tt.wantErr {
+			var invalid *validationError
+			if !errors.As(err, &invalid) {
+				t.Errorf("render with %v = %q, %v, want a validation error", tt.vars, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("render with %v = %q, %v, want %q", tt.vars, got, err, tt.want)
+		}
+	}
+}
+
+func TestMessageTemplateApplyOptional(t *testing.T) {
+	const text = "{{if .name}}{{.name}}، {{end}}فاتورتك {{.amount}}"
+	recipients := []recipient{
+		{Phone: "966500000001", Fields: map[string]string{"phone": "966500000001", "name": "علي"}, Source: "file:2"},
+		{Phone: "966500000002", Fields: map[string]string{"phone": "966500000002"}, Source: "--to"},
+	}
+	f := &messageTemplateFlags{Name: "invoice", Vars: templateVars{"amount": "50"}}
+	if err := f.apply(text, recipients); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	messages, err := buildSMSMessages(text, "S", recipients)
+	if err != nil {
+		t.Fatalf("buildSMSMessages: %v", err)
+	}
+	var texts []string
+	for _, m := range messages {
+		texts = append(texts, m.Text)
+	}
+	if want := []string{"علي، فاتورتك 50", "فاتورتك 50"}; !reflect.DeepEqual(texts, want) {
+		t.Errorf("texts = %q, want %q", texts, want)
+	}
+
+	missing := []recipient{{Phone: "966500000003", Fields: map[string]string{"phone": "966500000003"}, Source: "--to"}}
+	f = &messageTemplateFlags{Name: "invoice", Vars: templateVars{}}
+	if err := f.apply(text, missing); err == nil {
+		t.Error("apply without the required amount succeeded")
+	}
+}
+
+func TestTemplateListChannel(t *testing.T) {
+	t.Setenv("FOURJAWALY_TEMPLATES_FILE", t.TempDir()+"/templates.json")
+	defer func(prev outputMode) { output = prev }(output)
+	for _, ch := range []string{"all", "email", "SMS"} {
+		var usage *usageError
+		if err := runTemplateList([]string{"--channel", ch}); !errors.As(err, &usage) {
+			t.Errorf("template list --channel %s = %v, want a usage error", ch, err)
+		}
+	}
+	for _, ch := range []string{"", "sms", "wa"} {
+		if err := runTemplateList([]string{"--channel", ch, "--output", "quiet"}); err != nil {
+			t.Errorf("template list --channel %q = %v", ch, err)
+		}
+	}
+}
+
+func TestMessageTemplateRenderDollarField(t *testing.T) {
+	const text = "{{with .coupon}}كود {{.}} لـ {{$.name}}{{else}}رمزك {{$.code}}{{end}}"
+	f := &messageTemplateFlags{Name: "promo", Vars: templateVars{"coupon": "X1", "name": "سارة"}}
+	if got, err := f.render(text); err != nil || got != "كود X1 لـ سارة" {
+		t.Errorf("render = %q, %v", got, err)
+	}
+	f = &messageTemplateFlags{Name: "promo", Vars: templateVars{"code": "1234"}}
+	if got, err := f.render(text); err != nil || got != "رمزك 1234" {
+		t.Errorf("render = %q, %v", got, err)
+	}
+
+	f = &messageTemplateFlags{Name: "otp", Vars: templateVars{}}
+	var invalid *validationError
+	if got, err := f.render("رمزك {{$.code}}"); !errors.As(err, &invalid) || !strings.Contains(err.Error(), "code") {
+		t.Errorf("render without $.code = %q, %v, want a validation error naming code", got, err)
+	}
+}
+
+func TestTemplateStorePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("FOURJAWALY_TEMPLATES_FILE", "")
+	s, err := newTemplateStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, ".config", "4jawaly", "templates.json"); s.path != want {
+		t.Errorf("path = %q, want %q", s.path, want)
+	}
+}
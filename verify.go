@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+
+	"fourjawaly-cli/fourjawaly"
+)
+
+// Outcomes of one auth verify check.
+const (
+	checkOK      = "ok"
+	checkInvalid = "invalid"
+	checkMissing = "missing"
+	checkSkipped = "skipped"
+	checkError   = "error"
+)
+
+type verifyCheck struct {
+	Check  string `json:"check"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// err is what a failed check makes the command return.
+	err error
+}
+
+func runAuthVerify(args []string) error {
+	fs := flag.NewFlagSet("auth verify", flag.ContinueOnError)
+	appKeyFlag := fs.String("app-key", "", "مفتاح API")
+	apiSecretFlag := fs.String("api-secret", "", "سر API (أو - للقراءة من stdin)")
+	addSecretFileFlag(fs)
+	projectIDFlag := fs.String("project-id", "", "رقم مشروع واتساب")
+	smsBaseURLFlag := fs.String("sms-base-url", "", "رابط SMS API (الافتراضي: "+defaultSMSBaseURL+")")
+	waBaseURLFlag := fs.String("wa-base-url", "", "رابط WhatsApp API (الافتراضي: "+defaultWABaseURL+")")
+	addGlobalFlags(fs)
+	addRetryFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	smsCfg, err := resolveSMSConfig(*appKeyFlag, *apiSecretFlag, *smsBaseURLFlag)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	checks := []verifyCheck{verifySMS(ctx, smsCfg)}
+
+	waCfg, err := resolveWAConfig(*appKeyFlag, *apiSecretFlag, *projectIDFlag, *waBaseURLFlag)
+	var auth *authError
+	switch {
+	case errors.As(err, &auth) && waCfg.ProjectID == "":
+		checks = append(checks,
+			verifyCheck{Check: "wa_auth", Status: checkSkipped, Detail: "يحتاج project-id"},
+			// SMS-only setups have no project, so this alone does not fail.
+			verifyCheck{Check: "wa_project", Status: checkMissing, Detail: "مرّر --project-id أو FOURJAWALY_WHATSAPP_PROJECT_ID"},
+		)
+	case err != nil:
+		return err
+	default:
+		checks = append(checks, verifyWA(ctx, waCfg)...)
+	}
+
+	t := &table{header: []string{"check", "status", "detail"}}
+	var firstErr error
+	for _, c := range checks {
+		t.rows = append(t.rows, []string{c.Check, c.Status, c.Detail})
+		if output != outputTable && output != outputCSV {
+			progressf("%-11s %-8s %s\n", c.Check, c.Status, c.Detail)
+		}
+		if firstErr == nil {
+			firstErr = c.err
+		}
+	}
+	if err := emitValue(map[string]any{"checks": checks}, t); err != nil {
+		return err
+	}
+	return firstErr
+}
+
+// verifySMS lists the active packages, the cheapest authenticated SMS call.
+func verifySMS(ctx context.Context, cfg smsConfig) verifyCheck {
+	c := verifyCheck{Check: "sms_auth"}
+	_, err := cfg.client().Packages(ctx)
+	var apiErr *fourjawaly.APIError
+	switch {
+	case err == nil:
+		c.Status = checkOK
+	case errors.As(err, &apiErr) && apiErr.Unauthorized():
+		c.Status, c.Detail = checkInvalid, apiErr.Error()
+		c.err = &authError{msg: "بيانات الدخول مرفوضة من SMS API: " + apiErr.Error()}
+	default:
+		c.Status, c.Detail, c.err = checkError, err.Error(), err
+	}
+	return c
+}
+
+// verifyWA reads the project's business profile. A 401 means the keys were
+// refused; a 403 or 404 means they were accepted but the project is not
+// theirs or does not exist.
+func verifyWA(ctx context.Context, cfg waConfig) []verifyCheck {
+	authCheck := verifyCheck{Check: "wa_auth"}
+	project := verifyCheck{Check: "wa_project", Detail: cfg.ProjectID}
+
+	res, err := cfg.client().BusinessProfile(ctx)
+	var apiErr *fourjawaly.APIError
+	switch {
+	case err == nil:
+		authCheck.Status, project.Status = checkOK, checkOK
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized:
+		authCheck.Status, authCheck.Detail = checkInvalid, apiErr.Error()
+		authCheck.err = &authError{msg: "بيانات الدخول مرفوضة من WhatsApp API: " + apiErr.Error()}
+		project.Status = checkSkipped
+	case errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusForbidden || apiErr.StatusCode == http.StatusNotFound):
+		authCheck.Status = checkOK
+		project.Status, project.Detail = checkInvalid, fmt.Sprintf("%s: %s", cfg.ProjectID, apiErr.Error())
+		project.err = &authError{msg: fmt.Sprintf("project-id %s غير صحيح أو لا يتبع هذا الحساب: %s", cfg.ProjectID, apiErr.Error())}
+	case res != nil:
+		// The project answered, but WhatsApp itself reported an error, so the
+		// keys and project are fine and the number setup is not.
+		authCheck.Status, project.Status = checkOK, checkError
+		project.Detail = fmt.Sprintf("%s: %s", cfg.ProjectID, err.Error())
+		project.err = err
+	default:
+		authCheck.Status, authCheck.Detail, authCheck.err = checkError, err.Error(), err
+		project.Status = checkSkipped
+	}
+	return []verifyCheck{authCheck, project}
+}
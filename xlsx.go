@@ -0,0 +1,210 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// readXLSX returns the cell values of the first worksheet in an .xlsx file,
+// one slice per row. Only what recipient lists need is supported: shared,
+// inline and plain strings plus numbers; styles and formulas are ignored.
+func readXLSX(filename string) ([][]string, error) {
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := xlsxFirstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if shared, err = xlsxSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("xlsx: لم يتم العثور على %s", sheetPath)
+	}
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline struct {
+					Text string `xml:"t"`
+					Runs []struct {
+						Text string `xml:"t"`
+					} `xml:"r"`
+				} `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodeZipXML(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, r := range sheet.Rows {
+		var row []string
+		for i, c := range r.Cells {
+			col := i
+			if c.Ref != "" {
+				col = xlsxColumnIndex(c.Ref)
+				if col < 0 {
+					return nil, fmt.Errorf("xlsx: مرجع خلية غير صحيح %q", c.Ref)
+				}
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(strings.TrimSpace(c.Value))
+				if err != nil || idx < 0 || idx >= len(shared) {
+					return nil, fmt.Errorf("xlsx: مرجع نص غير صحيح في الخلية %s", c.Ref)
+				}
+				row[col] = shared[idx]
+			case "inlineStr":
+				text := c.Inline.Text
+				for _, run := range c.Inline.Runs {
+					text += run.Text
+				}
+				row[col] = text
+			case "str", "b", "e":
+				row[col] = c.Value
+			default:
+				row[col] = xlsxNumber(c.Value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func xlsxFirstSheet(files map[string]*zip.File) (string, error) {
+	var workbook struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+
+	wb, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", fmt.Errorf("xlsx: الملف لا يحتوي xl/workbook.xml")
+	}
+	if err := decodeZipXML(wb, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("xlsx: لا توجد أوراق عمل")
+	}
+	if rf, ok := files["xl/_rels/workbook.xml.rels"]; ok {
+		if err := decodeZipXML(rf, &rels); err != nil {
+			return "", err
+		}
+	}
+	for _, rel := range rels.Rels {
+		if rel.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "xl/worksheets/sheet1.xml", nil
+}
+
+func xlsxSharedStrings(f *zip.File) ([]string, error) {
+	var sst struct {
+		Items []struct {
+			Text string `xml:"t"`
+			Runs []struct {
+				Text string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := decodeZipXML(f, &sst); err != nil {
+		return nil, err
+	}
+	out := make([]string, len(sst.Items))
+	for i, si := range sst.Items {
+		text := si.Text
+		for _, run := range si.Runs {
+			text += run.Text
+		}
+		out[i] = text
+	}
+	return out, nil
+}
+
+// xlsxMaxColumns is the column limit of a worksheet (XFD).
+const xlsxMaxColumns = 16384
+
+// xlsxColumnIndex turns a cell reference such as "C12" into a 0-based column,
+// or -1 when it does not start with a column within the sheet limit.
+func xlsxColumnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			col = col*26 + int(r-'A'+1)
+		case r >= 'a' && r <= 'z':
+			col = col*26 + int(r-'a'+1)
+		default:
+			return col - 1
+		}
+		if col > xlsxMaxColumns {
+			return -1
+		}
+	}
+	return col - 1
+}
+
+// xlsxNumber renders a numeric cell the way it was typed: phone numbers are
+// often stored as numbers and may come back in exponent form.
+func xlsxNumber(v string) string {
+	v = strings.TrimSpace(v)
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || !strings.ContainsAny(v, "eE.") {
+		return v
+	}
+	if f == float64(int64(f)) {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return v
+}
+
+func decodeZipXML(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("xlsx: %s: %w", f.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeXLSX builds a minimal workbook whose first sheet holds sheetData and,
+// when shared is not empty, a shared string table.
+func writeXLSX(t *testing.T, sheetData string, shared ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "list.xlsx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	if len(shared) > 0 {
+		var sst strings.Builder
+		sst.WriteString(`<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+		for _, s := range shared {
+			sst.WriteString("<si>" + s + "</si>")
+		}
+		sst.WriteString("</sst>")
+		files["xl/sharedStrings.xml"] = sst.String()
+	}
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadXLSX(t *testing.T) {
+	tests := []struct {
+		name   string
+		sheet  string
+		shared []string
+		want   [][]string
+	}{
+		{
+			name:   "shared strings",
+			sheet:  `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row><row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2" t="s"><v>3</v></c></row>`,
+			shared: []string{"<t>name</t>", "<t>phone</t>", "<r><t>سا</t></r><r><t>رة</t></r>", "<t>0501234567</t>"},
+			want:   [][]string{{"name", "phone"}, {"سارة", "0501234567"}},
+		},
+		{
+			name:  "inline strings",
+			sheet: `<row><c r="A1" t="inlineStr"><is><t>phone</t></is></c></row><row><c r="A2" t="inlineStr"><is><r><t>050</t></r><r><t>1234567</t></r></is></c></row>`,
+			want:  [][]string{{"phone"}, {"0501234567"}},
+		},
+		{
+			name: "numeric phone cells",
+			sheet: `<row><c r="A1" t="str"><v>phone</v></c></row>` +
+				`<row><c r="A2"><v>966501234567</v></c></row>` +
+				`<row><c r="A3" t="n"><v>9.66501234567E11</v></c></row>` +
+				`<row><c r="A4"><v>966501234567.0</v></c></row>` +
+				`<row><c r="A5"><v>12.5</v></c></row>`,
+			want: [][]string{{"phone"}, {"966501234567"}, {"966501234567"}, {"966501234567"}, {"12.5"}},
+		},
+		{
+			name:  "sparse refs",
+			sheet: `<row><c r="B1" t="str"><v>phone</v></c><c r="D1" t="str"><v>name</v></c></row><row><c r="D2" t="str"><v>علي</v></c></row><row></row>`,
+			want:  [][]string{{"", "phone", "", "name"}, {"", "", "", "علي"}, nil},
+		},
+		{
+			name:  "cells without refs",
+			sheet: `<row><c t="str"><v>phone</v></c><c t="str"><v>name</v></c></row>`,
+			want:  [][]string{{"phone", "name"}},
+		},
+		{
+			name:  "lowercase refs",
+			sheet: `<row><c r="b1" t="str"><v>x</v></c><c r="d1" t="str"><v>y</v></c></row>`,
+			want:  [][]string{{"", "x", "", "y"}},
+		},
+		{
+			name:  "booleans and errors as stored",
+			sheet: `<row><c r="A1" t="b"><v>1</v></c><c r="B1" t="e"><v>#N/A</v></c></row>`,
+			want:  [][]string{{"1", "#N/A"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readXLSX(writeXLSX(t, tt.sheet, tt.shared...))
+			if err != nil {
+				t.Fatalf("readXLSX: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readXLSX =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadXLSXErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		sheet  string
+		shared []string
+		want   string
+	}{
+		{name: "ref without a column", sheet: `<row><c r="12" t="str"><v>x</v></c></row>`, want: "مرجع خلية غير صحيح"},
+		{name: "ref with a symbol", sheet: `<row><c r="$A$1" t="str"><v>x</v></c></row>`, want: "مرجع خلية غير صحيح"},
+		{name: "column beyond the sheet", sheet: `<row><c r="ZZZZZZZZ1" t="str"><v>x</v></c></row>`, want: "مرجع خلية غير صحيح"},
+		{name: "shared string out of range", sheet: `<row><c r="A1" t="s"><v>3</v></c></row>`, shared: []string{"<t>a</t>"}, want: "مرجع نص غير صحيح"},
+		{name: "shared string without a table", sheet: `<row><c r="A1" t="s"><v>0</v></c></row>`, want: "مرجع نص غير صحيح"},
+		{name: "malformed sheet", sheet: `<row><c r="A1">`, want: "sheet1.xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := readXLSX(writeXLSX(t, tt.sheet, tt.shared...))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("readXLSX = %q, %v, want an error containing %q", rows, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := readXLSX(writeTestFile(t, "list.xlsx", "not a zip")); err == nil {
+		t.Error("readXLSX on a non-zip file succeeded")
+	}
+}
+
+func TestXLSXColumnIndex(t *testing.T) {
+	for ref, want := range map[string]int{
+		"A1": 0, "b7": 1, "Z1": 25, "AA1": 26, "az3": 51, "XFD1": 16383, "A": 0,
+		"XFE1": -1, "1": -1, "": -1, "$A1": -1,
+	} {
+		if got := xlsxColumnIndex(ref); got != want {
+			t.Errorf("xlsxColumnIndex(%q) = %d, want %d", ref, got, want)
+		}
+	}
+}
+
+func TestReadXLSXRecipients(t *testing.T) {
+	path := writeXLSX(t,
+		`<row><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>`+
+			`<row><c r="A2" t="s"><v>2</v></c><c r="C2"><v>966501234567</v></c></row>`+
+			`<row><c r="A3" t="s"><v>2</v></c></row>`,
+		"<t>الاسم</t>", "<t>الجوال</t>", "<t>سارة</t>")
+	got, err := readXLSXRecipients(path, "")
+	if err != nil {
+		t.Fatalf("readXLSXRecipients: %v", err)
+	}
+	if p := phones(got); !reflect.DeepEqual(p, []string{"966501234567@2"}) {
+		t.Fatalf("recipients = %v", p)
+	}
+	if got[0].Fields["الاسم"] != "سارة" {
+		t.Errorf("fields = %v", got[0].Fields)
+	}
+}